@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/session"
+)
+
+const CACHE_DIR = "cache"
+
+// Set from `Cache` unless `--no-cache` is given
+var CacheResponses bool
+
+type cachedResponse struct {
+	Created time.Time `json:"created"`
+	Model   string    `json:"model"`
+	Content string    `json:"content"`
+}
+
+// Hashes everything that affects the response: the messages, model and
+// parameters. Streamed and non-streamed requests share their entries.
+func cacheKey(req openai.ChatCompletionRequest) string {
+	req.Stream = false
+	req.StreamOptions = nil
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func cachePath(req openai.ChatCompletionRequest) string {
+	return filepath.Join(CACHE_DIR, cacheKey(req)+".json")
+}
+
+func cachedCompletion(req openai.ChatCompletionRequest) (string, bool) {
+	if !CacheResponses {
+		return "", false
+	}
+	data, err := session.ReadSecureFile(cachePath(req))
+	if err != nil {
+		return "", false
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return "", false
+	}
+	return cached.Content, true
+}
+
+func storeCompletion(req openai.ChatCompletionRequest, content string) {
+	if !CacheResponses || content == "" {
+		return
+	}
+	data, err := json.Marshal(cachedResponse{Created: time.Now(), Model: req.Model, Content: content})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(CACHE_DIR, 0755); err != nil {
+		return
+	}
+	session.WriteSecureFile(cachePath(req), data)
+}
@@ -0,0 +1,216 @@
+// Package chat sends completion requests to the API, with response caching,
+// tracing and metrics.
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/config"
+)
+
+// Called with the stats of the requests sent by CompleteMessages, e.g. to
+// record their cost
+var OnUsage = func(stats ResponseStats) {}
+
+// Applied to the user messages sent by CompleteMessages, returns the content
+// with redactions applied, ok is false if it is blocked
+var Filter = func(content string) (_ string, ok bool) { return content, true }
+
+var ErrFiltered = errors.New("the prompt was blocked by a filter")
+
+// Limits every API request, including the streaming of the response, 0
+// disables it. It is read by each request, so that changes apply to the next
+// one.
+var Timeout time.Duration
+
+// A context limited by Timeout, for the requests sent outside of this package
+func Context() (context.Context, context.CancelFunc) {
+	return withTimeout(context.Background())
+}
+
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, Timeout)
+}
+
+// Chat requests use the request parameters of the config
+func NewRequest(config config.Config, model string, messages []openai.ChatCompletionMessage) openai.ChatCompletionRequest {
+	req := openai.ChatCompletionRequest{
+		Model:    model,
+		Messages: messages,
+		Stop:     config.Stop,
+	}
+	if config.Seed != 0 {
+		seed := config.Seed
+		req.Seed = &seed
+	}
+	if config.Temperature != 0 {
+		req.Temperature = float32(config.Temperature)
+	}
+	if config.MaxTokens != 0 {
+		req.MaxTokens = config.MaxTokens
+	}
+	return req
+}
+
+// Streams a completion, calling onChunk for every piece of content received
+// and onToolCall for every piece of a tool call, as the model writes them
+func StreamCompletion(client *openai.Client, req openai.ChatCompletionRequest, onChunk func(string), onToolCall func(index int, name string, args string)) (_ string, _ []openai.ToolCall, stats ResponseStats, err error) {
+	req.Stream = true
+	req.StreamOptions = &openai.StreamOptions{
+		IncludeUsage: true,
+	}
+
+	stats = ResponseStats{Model: req.Model}
+	if req.Seed != nil {
+		stats.Seed = *req.Seed
+	}
+	ctx, span := startCompletionSpan(req.Model, true)
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	defer func() {
+		if err != nil {
+			observeRequestError(req.Model)
+		}
+		endCompletionSpan(span, stats, err)
+	}()
+	if content, ok := cachedCompletion(req); ok {
+		stats.Cached = true
+		if onChunk != nil {
+			onChunk(content)
+		}
+		return content, nil, stats, nil
+	}
+	start := time.Now()
+
+	stream, err := client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return "", nil, stats, err
+	}
+	defer stream.Close()
+
+	response := strings.Builder{}
+	toolCalls := []openai.ToolCall{}
+	for {
+		streamResponse, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		// The partial response of a dropped connection or a timeout is
+		// returned along with the error, and not cached
+		if recvErr != nil {
+			stats.Duration = time.Since(start)
+			return response.String(), nil, stats, recvErr
+		}
+		if streamResponse.SystemFingerprint != "" {
+			stats.SystemFingerprint = streamResponse.SystemFingerprint
+		}
+		// The last chunk only carries usage and has no choices
+		if streamResponse.Usage != nil {
+			stats.PromptTokens = streamResponse.Usage.PromptTokens
+			stats.CompletionTokens = streamResponse.Usage.CompletionTokens
+		}
+		if len(streamResponse.Choices) == 0 {
+			continue
+		}
+		delta := streamResponse.Choices[0].Delta
+		// Tool calls arrive in pieces, identified by their index
+		for _, call := range delta.ToolCalls {
+			index := max(len(toolCalls)-1, 0)
+			if call.Index != nil {
+				index = *call.Index
+			}
+			for len(toolCalls) <= index {
+				toolCalls = append(toolCalls, openai.ToolCall{Type: openai.ToolTypeFunction})
+			}
+			if call.ID != "" {
+				toolCalls[index].ID = call.ID
+			}
+			toolCalls[index].Function.Name += call.Function.Name
+			toolCalls[index].Function.Arguments += call.Function.Arguments
+			if onToolCall != nil {
+				onToolCall(index, call.Function.Name, call.Function.Arguments)
+			}
+		}
+		chunk := delta.Content
+		if stats.TimeToFirstToken == 0 && chunk != "" {
+			stats.TimeToFirstToken = time.Since(start)
+		}
+		response.WriteString(chunk)
+		if onChunk != nil {
+			onChunk(chunk)
+		}
+	}
+	stats.Duration = time.Since(start)
+
+	// Responses calling tools depend on the result of the calls
+	if len(toolCalls) == 0 {
+		storeCompletion(req, response.String())
+	}
+	return response.String(), toolCalls, stats, nil
+}
+
+func Complete(client *openai.Client, model string, system string, user string) (string, error) {
+	return CompleteMessages(client, model, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: system},
+		{Role: openai.ChatMessageRoleUser, Content: user},
+	})
+}
+
+// Like complete, for multi-turn exchanges outside of the conversation
+func CompleteMessages(client *openai.Client, model string, messages []openai.ChatCompletionMessage) (string, error) {
+	messages = append([]openai.ChatCompletionMessage{}, messages...)
+	for i, msg := range messages {
+		if msg.Role != openai.ChatMessageRoleUser {
+			continue
+		}
+		content, ok := Filter(msg.Content)
+		if !ok {
+			return "", ErrFiltered
+		}
+		messages[i].Content = content
+	}
+	req := openai.ChatCompletionRequest{
+		Model:    model,
+		Messages: messages,
+	}
+	ctx, span := startCompletionSpan(model, false)
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	stats := ResponseStats{Model: model}
+	var err error
+	defer func() {
+		if err != nil {
+			observeRequestError(model)
+		}
+		endCompletionSpan(span, stats, err)
+	}()
+	if content, ok := cachedCompletion(req); ok {
+		stats.Cached = true
+		return strings.TrimSpace(content), nil
+	}
+	start := time.Now()
+	resp, err := client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		err = fmt.Errorf("empty response")
+		return "", err
+	}
+	stats.Duration = time.Since(start)
+	stats.PromptTokens = resp.Usage.PromptTokens
+	stats.CompletionTokens = resp.Usage.CompletionTokens
+	OnUsage(stats)
+	storeCompletion(req, resp.Choices[0].Message.Content)
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,72 @@
+package chat
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Streams two chunks of a response, then ends the stream or drops the
+// connection
+func streamServer(t *testing.T, complete bool) *openai.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{"Hello", " world"} {
+			fmt.Fprintf(w, "data: {\"choices\": [{\"index\": 0, \"delta\": {\"content\": %q}}]}\n\n", chunk)
+		}
+		w.(http.Flusher).Flush()
+		if !complete {
+			panic(http.ErrAbortHandler)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+	config := openai.DefaultConfig("key")
+	config.BaseURL = server.URL
+	return openai.NewClientWithConfig(config)
+}
+
+func TestStreamCompletion(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	CacheResponses = true
+	defer func() { CacheResponses = false }()
+
+	tests := []struct {
+		name     string
+		complete bool
+		cached   bool
+	}{
+		{"dropped connection", false, false},
+		{"complete", true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := openai.ChatCompletionRequest{
+				Model:    "gpt-4o-mini",
+				Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+			}
+			response, _, _, err := StreamCompletion(streamServer(t, test.complete), req, nil, nil)
+			if test.complete != (err == nil) {
+				t.Errorf("got error %v", err)
+			}
+			if response != "Hello world" {
+				t.Errorf("got response %q", response)
+			}
+			if _, cached := cachedCompletion(req); cached != test.cached {
+				t.Errorf("got cached %v, want %v", cached, test.cached)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package chat
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"gpt/provider"
+)
+
+// Every request goes to the OpenAI API for now
+const PROVIDER = "openai"
+
+var (
+	requestsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gpt_requests_total",
+		Help: "API requests, by model and status (ok or error).",
+	}, []string{"model", "provider", "status"})
+	tokensMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gpt_tokens_total",
+		Help: "Tokens used, by model and type (prompt or completion).",
+	}, []string{"model", "provider", "type"})
+	costMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gpt_cost_usd_total",
+		Help: "Cost of the requests in USD, by model.",
+	}, []string{"model", "provider"})
+	latencyMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gpt_request_latency_seconds",
+		Help:    "Latency of the requests, to the first token for streamed responses.",
+		Buckets: []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32},
+	}, []string{"model", "provider"})
+)
+
+func ObserveRequest(stats ResponseStats) {
+	requestsMetric.WithLabelValues(stats.Model, PROVIDER, "ok").Inc()
+	tokensMetric.WithLabelValues(stats.Model, PROVIDER, "prompt").Add(float64(stats.PromptTokens))
+	tokensMetric.WithLabelValues(stats.Model, PROVIDER, "completion").Add(float64(stats.CompletionTokens))
+	costMetric.WithLabelValues(stats.Model, PROVIDER).Add(provider.ComputeCost(stats.Model, stats.PromptTokens, stats.CompletionTokens))
+	latencyMetric.WithLabelValues(stats.Model, PROVIDER).Observe(RequestLatency(stats).Seconds())
+}
+
+func observeRequestError(model string) {
+	requestsMetric.WithLabelValues(model, PROVIDER, "error").Inc()
+}
@@ -0,0 +1,34 @@
+package chat
+
+import "time"
+
+type ResponseStats struct {
+	Model             string
+	TimeToFirstToken  time.Duration
+	Duration          time.Duration
+	PromptTokens      int
+	CompletionTokens  int
+	Seed              int
+	SystemFingerprint string
+	// Cached responses cost nothing and are not recorded
+	Cached bool
+}
+
+func (s ResponseStats) TokensPerSecond() float64 {
+	// Generation speed is measured from the first token, so that network
+	// latency doesn't skew the comparison between providers
+	generation := s.Duration - s.TimeToFirstToken
+	if generation <= 0 || s.CompletionTokens == 0 {
+		return 0
+	}
+	return float64(s.CompletionTokens) / generation.Seconds()
+}
+
+// Streamed responses are measured to the first token, so that the length of
+// the response doesn't count
+func RequestLatency(r ResponseStats) time.Duration {
+	if r.TimeToFirstToken > 0 {
+		return r.TimeToFirstToken
+	}
+	return r.Duration
+}
@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"gpt/config"
+)
+
+const SERVICE_NAME = "go-gpt"
+
+// Spans are dropped until setupTracing installs an exporter
+var Tracer = otel.Tracer(SERVICE_NAME)
+
+// Exports spans with OTLP over HTTP to `TracingEndpoint`, e.g.
+// `http://localhost:4318`. The returned function flushes the pending spans.
+func SetupTracing(config config.Config) func() {
+	if config.TracingEndpoint == "" {
+		return func() {}
+	}
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(strings.TrimSuffix(config.TracingEndpoint, "/")+"/v1/traces"))
+	if err != nil {
+		fmt.Printf("Error setting up tracing: %v\n", err)
+		return func() {}
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(SERVICE_NAME))),
+	)
+	otel.SetTracerProvider(provider)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			fmt.Printf("Error exporting spans: %v\n", err)
+		}
+	}
+}
+
+// Starts the span of a completion request, endSpan records its outcome
+func startCompletionSpan(model string, stream bool) (context.Context, trace.Span) {
+	return Tracer.Start(context.Background(), "chat "+model, trace.WithAttributes(
+		attribute.String("gen_ai.operation.name", "chat"),
+		attribute.String("gen_ai.request.model", model),
+		attribute.Bool("stream", stream),
+	))
+}
+
+func endCompletionSpan(span trace.Span, stats ResponseStats, err error) {
+	span.SetAttributes(
+		attribute.Int("gen_ai.usage.input_tokens", stats.PromptTokens),
+		attribute.Int("gen_ai.usage.output_tokens", stats.CompletionTokens),
+		attribute.Bool("cached", stats.Cached),
+	)
+	EndSpan(span, err)
+}
+
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gpt/i18n"
+	"gpt/render"
+)
+
+// Prints a response as it is streamed, or all at once once complete in
+// accessible mode, so that screen readers don't read it piece by piece
+type responsePrinter struct {
+	buffer strings.Builder
+}
+
+func (p *responsePrinter) chunk(chunk string) {
+	if render.Accessible {
+		p.buffer.WriteString(chunk)
+		return
+	}
+	fmt.Print(chunk)
+}
+
+// In accessible mode, the response is announced by the role
+func (p *responsePrinter) done() {
+	if !render.Accessible || p.buffer.Len() == 0 {
+		return
+	}
+	fmt.Println(i18n.T("Assistant:"))
+	fmt.Print(p.buffer.String())
+	p.buffer.Reset()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/chzyer/readline"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/render"
+	"gpt/session"
+)
+
+const (
+	MAX_ALTERNATIVES = 9
+	// Below this width candidates are printed one after the other
+	MIN_COLUMN_WIDTH = 30
+)
+
+var alternativeStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+
+// Requests n completions of the prompt and lets the user pick the one that
+// enters the history. The prompt is discarded along with the candidates if
+// none is picked.
+func runAlternatives(client *openai.Client, config config.Config, n int, prompt string) (string, bool) {
+	appendMessage(session.NewMessage(openai.ChatMessageRoleUser, prompt))
+
+	req := chat.NewRequest(config, config.Model, buildMessages(config))
+	req.N = n
+	start := time.Now()
+	ctx, cancel := chat.Context()
+	defer cancel()
+	resp, err := client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		fmt.Printf("Error requesting alternatives: %v\n", err)
+		dropLastMessage()
+		return "", false
+	}
+	stats := chat.ResponseStats{
+		Model:             config.Model,
+		Duration:          time.Since(start),
+		PromptTokens:      resp.Usage.PromptTokens,
+		CompletionTokens:  resp.Usage.CompletionTokens,
+		Seed:              config.Seed,
+		SystemFingerprint: resp.SystemFingerprint,
+	}
+	recordUsage(stats)
+	if len(resp.Choices) == 0 {
+		fmt.Println("Error requesting alternatives: empty response")
+		dropLastMessage()
+		return "", false
+	}
+
+	candidates := make([]string, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		// Not every provider numbers the choices
+		if choice.Index < 0 || choice.Index >= len(candidates) {
+			choice.Index = i
+		}
+		candidates[choice.Index] = choice.Message.Content
+	}
+	printAlternatives(candidates)
+
+	answer, _ := ask(i18n.T("Keep which alternative? [1-%d, Enter to discard all] ", len(candidates)))
+	picked, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || picked < 1 || picked > len(candidates) {
+		fmt.Println("Discarded all alternatives")
+		dropLastMessage()
+		return "", false
+	}
+	response := candidates[picked-1]
+	if !moderate(client, config, MODERATE_RESPONSES, response) {
+		fmt.Println("The prompt and response were removed from the history")
+		dropLastMessage()
+		return "", false
+	}
+	appendMessage(newResponseMessage(response, stats))
+	sessionStats.CountMessage(openai.ChatMessageRoleUser)
+	sessionStats.CountMessage(openai.ChatMessageRoleAssistant)
+	return response, true
+}
+
+// Shows the candidates side by side when the terminal is wide enough, one
+// after the other otherwise or in accessible mode
+func printAlternatives(candidates []string) {
+	width := readline.GetScreenWidth()/len(candidates) - alternativeStyle.GetHorizontalFrameSize()
+	if width < MIN_COLUMN_WIDTH || render.Accessible {
+		for i, candidate := range candidates {
+			fmt.Printf("\n--- Alternative %d ---\n%s\n", i+1, candidate)
+		}
+		return
+	}
+	columns := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		columns[i] = alternativeStyle.Width(width).Render(fmt.Sprintf("%d.\n\n%s", i+1, candidate))
+	}
+	fmt.Println(lipgloss.JoinHorizontal(lipgloss.Top, columns...))
+}
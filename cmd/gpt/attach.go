@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/config"
+	"gpt/i18n"
+)
+
+// Paths the user chose not to attach are not offered again
+var declinedPaths = map[string]bool{}
+
+// Splits a line into words like a shell, as terminals quote or escape the
+// paths dropped on them
+func shellWords(line string) []string {
+	words := []string{}
+	word := strings.Builder{}
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\' && runtime.GOOS != "windows":
+			escaped, inWord = true, true
+		// Apostrophes inside words, as in "what's", are not quotes
+		case (r == '\'' || r == '"') && !inWord:
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return strings.Fields(line)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// Returns the path a word refers to, if any. Only words with a separator and
+// a name are paths, so that `.`, `/` or a file name used in a sentence are
+// not offered.
+func wordPath(word string) (string, bool) {
+	if strings.HasPrefix(word, "file://") {
+		if u, err := url.Parse(word); err == nil {
+			word = u.Path
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(word, "~/") {
+		word = filepath.Join(home, word[2:])
+	}
+	for _, candidate := range []string{word, strings.TrimRight(word, ".,;:!?)")} {
+		if !strings.ContainsRune(candidate, filepath.Separator) {
+			continue
+		}
+		switch filepath.Base(filepath.Clean(candidate)) {
+		case ".", "..", string(filepath.Separator):
+			continue
+		}
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// Returns the existing paths of a line, and whether it only consists of paths
+func droppedPaths(line string) ([]string, bool) {
+	paths := []string{}
+	words := shellWords(line)
+	for _, word := range words {
+		if path, ok := wordPath(word); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths, len(paths) > 0 && len(paths) == len(words)
+}
+
+// Offers to embed the paths that are not embedded yet
+func attachPaths(client *openai.Client, config *config.Config, paths []string) {
+	for _, path := range paths {
+		if findContextItem(path) != -1 || declinedPaths[path] {
+			continue
+		}
+		if !confirm(i18n.T("Attach `%s`?", path)) {
+			declinedPaths[path] = true
+			continue
+		}
+		embedPath(client, config, path)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/session"
+)
+
+const TRANSCRIPTION_MODEL = openai.Whisper1
+
+// Sent as the next user message instead of reading a line, e.g. a transcript
+var pendingInput string
+
+func transcribe(client *openai.Client, path string) (string, error) {
+	ctx, cancel := chat.Context()
+	defer cancel()
+	resp, err := client.CreateTranscription(ctx, openai.AudioRequest{
+		Model:    TRANSCRIPTION_MODEL,
+		FilePath: path,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// Handles `/transcribe <file> [--context | --send]`
+func transcribeCommand(client *openai.Client, args []string) bool {
+	if len(args) == 0 || len(args) > 2 {
+		return false
+	}
+	mode := ""
+	if len(args) == 2 {
+		mode = args[1]
+		if mode != "--context" && mode != "--send" {
+			return false
+		}
+	}
+
+	path := args[0]
+	fmt.Printf("Transcribing `%s`...\n", path)
+	text, err := transcribe(client, path)
+	if err != nil {
+		fmt.Printf("Error transcribing `%s`: %v\n", path, err)
+		return true
+	}
+	fmt.Println(text)
+
+	switch mode {
+	case "--context":
+		name := "transcript of " + filepath.Base(path)
+		addContextItem(session.ContextItem{Name: name, Kind: session.CONTEXT_TRANSCRIPT, Path: path, Content: text})
+		fmt.Printf("Added `%s` to system prompt\n", name)
+	case "--send":
+		pendingInput = text
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	BRACKETED_PASTE_ON  = "\x1b[?2004h"
+	BRACKETED_PASTE_OFF = "\x1b[?2004l"
+)
+
+var (
+	pasteStart = []byte("\x1b[200~")
+	pasteEnd   = []byte("\x1b[201~")
+)
+
+// With bracketed paste enabled, the terminal surrounds pasted text with
+// pasteStart and pasteEnd. pasteReader sits between stdin and readline and
+// replaces multi-line pastes with a placeholder.
+type pasteReader struct {
+	r       io.ReadCloser
+	in      []byte
+	out     []byte
+	paste   []byte
+	inPaste bool
+}
+
+func newPasteReader(r io.ReadCloser) *pasteReader {
+	return &pasteReader{r: r}
+}
+
+func enableBracketedPaste(enable bool) {
+	if enable {
+		fmt.Print(BRACKETED_PASTE_ON)
+	} else {
+		fmt.Print(BRACKETED_PASTE_OFF)
+	}
+}
+
+func (p *pasteReader) Read(b []byte) (int, error) {
+	buf := make([]byte, 4096)
+	for len(p.out) == 0 {
+		n, err := p.r.Read(buf)
+		p.in = append(p.in, buf[:n]...)
+		p.process()
+		if err != nil && len(p.out) == 0 {
+			return 0, err
+		}
+	}
+	n := copy(b, p.out)
+	p.out = p.out[n:]
+	return n, nil
+}
+
+func (p *pasteReader) Close() error {
+	return p.r.Close()
+}
+
+func (p *pasteReader) process() {
+	for {
+		marker := pasteStart
+		if p.inPaste {
+			marker = pasteEnd
+		}
+		idx := bytes.Index(p.in, marker)
+		if idx == -1 {
+			// Keep what could be the beginning of a marker for the next read
+			keep := partialMarker(p.in, marker)
+			p.consume(p.in[:len(p.in)-keep])
+			p.in = p.in[len(p.in)-keep:]
+			return
+		}
+		p.consume(p.in[:idx])
+		p.in = p.in[idx+len(marker):]
+		if p.inPaste {
+			p.out = append(p.out, pastedInput(string(p.paste))...)
+			p.paste = nil
+		}
+		p.inPaste = !p.inPaste
+	}
+}
+
+func (p *pasteReader) consume(data []byte) {
+	if p.inPaste {
+		p.paste = append(p.paste, data...)
+	} else {
+		p.out = append(p.out, data...)
+	}
+}
+
+// Returns the length of the longest suffix of data that starts marker
+func partialMarker(data []byte, marker []byte) int {
+	for n := min(len(data), len(marker)-1); n > 0; n-- {
+		if bytes.HasPrefix(marker, data[len(data)-n:]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// Returns what readline receives for a paste. Multi-line pastes become a
+// placeholder, fenced if they look like code, so that they are sent as one
+// message instead of one message per line. Single lines are typed without
+// their line break, to be edited before sending.
+func pastedInput(raw string) []byte {
+	text := strings.Trim(strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\r", "\n"), "\n")
+	if !strings.Contains(text, "\n") {
+		return []byte(text)
+	}
+	if needsFence(text) {
+		text = fenceCode(text)
+	}
+	return []byte(addPaste(text))
+}
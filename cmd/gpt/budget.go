@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gpt/config"
+)
+
+const BUDGET_WARNING_RATIO = 0.8
+
+// Set by `/budget override`, lifts the hard stop for the rest of the session
+var budgetOverride = false
+
+func sessionSpend() float64 {
+	total := 0.0
+	for _, usage := range sessionStats.Models {
+		total += usage.Cost
+	}
+	return total
+}
+
+func monthlySpend() (float64, error) {
+	entries, err := readUsage()
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	total := 0.0
+	for _, entry := range entries {
+		if entry.Time.Year() == now.Year() && entry.Time.Month() == now.Month() {
+			total += entry.Cost
+		}
+	}
+	return total, nil
+}
+
+// Returns false if the request must not be sent
+func checkBudget(config config.Config) bool {
+	allowed := checkLimit("Session", sessionSpend(), config.SessionBudget, config.CommandPrefix)
+	if config.MonthlyBudget > 0 {
+		spent, err := monthlySpend()
+		if err != nil {
+			fmt.Printf("Error reading usage ledger: %v\n", err)
+		}
+		allowed = checkLimit("Monthly", spent, config.MonthlyBudget, config.CommandPrefix) && allowed
+	}
+	return allowed
+}
+
+func checkLimit(name string, spent float64, limit float64, prefix string) bool {
+	if limit <= 0 {
+		return true
+	}
+	if spent >= limit {
+		if budgetOverride {
+			return true
+		}
+		fmt.Printf("%s budget exceeded ($%.4f / $%.2f), use `%sbudget override` to send anyway\n", name, spent, limit, prefix)
+		return false
+	}
+	if spent >= limit*BUDGET_WARNING_RATIO {
+		fmt.Printf("Warning: %.0f%% of the %s budget used ($%.4f / $%.2f)\n", spent/limit*100, name, spent, limit)
+	}
+	return true
+}
+
+func printBudget(config config.Config) {
+	printSpend := func(name string, spent float64, limit float64) {
+		if limit > 0 {
+			fmt.Printf("%s: $%.4f / $%.2f (%.0f%%)\n", name, spent, limit, spent/limit*100)
+		} else {
+			fmt.Printf("%s: $%.4f (no limit)\n", name, spent)
+		}
+	}
+
+	printSpend("Session", sessionSpend(), config.SessionBudget)
+	spent, err := monthlySpend()
+	if err != nil {
+		fmt.Printf("Error reading usage ledger: %v\n", err)
+		return
+	}
+	printSpend("Monthly", spent, config.MonthlyBudget)
+	if budgetOverride {
+		fmt.Println("Budget override is active for this session")
+	}
+}
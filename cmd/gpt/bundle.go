@@ -0,0 +1,191 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pelletier/go-toml"
+
+	"gpt/config"
+	"gpt/session"
+)
+
+const BUNDLE_VERSION = 1
+
+// A bundle is a zip archive with everything needed to reproduce a session
+type bundleManifest struct {
+	Version       int       `json:"version"`
+	Created       time.Time `json:"created"`
+	EmbeddedFiles []string  `json:"embedded_files"`
+}
+
+// The part of the config that affects the conversation itself
+type bundleConfig struct {
+	Model              string
+	SystemPrompt       string
+	MaxHistoryMessages int
+	Temperature        float64
+	Seed               int
+	Stop               []string
+	MaxTokens          int
+}
+
+func exportBundle(config config.Config, path string) {
+	archiveSession(config)
+	sess := *currentSession
+	sess.Model = config.Model
+	sess.SystemPrompt = config.SystemPrompt
+
+	buf := bytes.Buffer{}
+	w := zip.NewWriter(&buf)
+	writeJSON := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeZipFile(w, name, data)
+	}
+
+	err := writeJSON("manifest.json", bundleManifest{
+		Version:       BUNDLE_VERSION,
+		Created:       time.Now(),
+		EmbeddedFiles: contextNames(),
+	})
+	if err == nil {
+		err = writeJSON("session.json", sess)
+	}
+	if err == nil {
+		// Memories and the conversation summary are included, since the
+		// recipient of the bundle doesn't have them
+		err = writeZipFile(w, "system_prompt.txt", []byte(systemPrompt(config)))
+	}
+	if err == nil {
+		var data []byte
+		data, err = toml.Marshal(bundleConfig{
+			Model:              config.Model,
+			SystemPrompt:       config.SystemPrompt,
+			MaxHistoryMessages: config.MaxHistoryMessages,
+			Temperature:        config.Temperature,
+			Seed:               config.Seed,
+			Stop:               config.Stop,
+			MaxTokens:          config.MaxTokens,
+		})
+		if err == nil {
+			err = writeZipFile(w, "config.toml", data)
+		}
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		fmt.Printf("Error creating bundle: %v\n", err)
+		return
+	}
+	if err := session.WriteSecureFile(path, buf.Bytes()); err != nil {
+		fmt.Printf("Error writing `%s`: %v\n", path, err)
+		return
+	}
+	fmt.Printf("Session exported to `%s`\n", path)
+}
+
+func writeZipFile(w *zip.Writer, name string, data []byte) error {
+	f, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func readZipFile(r *zip.Reader, name string) ([]byte, error) {
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func readBundle(data []byte) (*session.Session, bundleManifest, bundleConfig, error) {
+	var manifest bundleManifest
+	var bundled bundleConfig
+
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, manifest, bundled, err
+	}
+	content, err := readZipFile(r, "manifest.json")
+	if err != nil {
+		return nil, manifest, bundled, err
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, manifest, bundled, err
+	}
+	if manifest.Version > BUNDLE_VERSION {
+		return nil, manifest, bundled, fmt.Errorf("bundle version %d is newer than the supported version %d", manifest.Version, BUNDLE_VERSION)
+	}
+
+	content, err = readZipFile(r, "session.json")
+	if err != nil {
+		return nil, manifest, bundled, err
+	}
+	var session session.Session
+	if err := json.Unmarshal(content, &session); err != nil {
+		return nil, manifest, bundled, err
+	}
+	// The effective system prompt already contains the embedded files
+	if prompt, err := readZipFile(r, "system_prompt.txt"); err == nil {
+		session.SystemPrompt = string(prompt)
+		session.Context = nil
+	}
+	if content, err := readZipFile(r, "config.toml"); err == nil {
+		if err := toml.Unmarshal(content, &bundled); err != nil {
+			return nil, manifest, bundled, err
+		}
+	}
+	// The system prompt of the config lacks the memories and the summary
+	if session.SystemPrompt == "" {
+		session.SystemPrompt = bundled.SystemPrompt
+	}
+	session.ID = "bundle-" + session.ID
+	return &session, manifest, bundled, nil
+}
+
+func importBundle(path string, data []byte) ([]*session.Session, error) {
+	sess, _, _, err := readBundle(data)
+	if err != nil {
+		return nil, err
+	}
+	return []*session.Session{sess}, nil
+}
+
+// Resumes the session of a bundle, applying its config for this session only
+func loadBundle(config *config.Config, path string) {
+	data, err := session.ReadSecureFile(path)
+	if err != nil {
+		fmt.Printf("Error reading `%s`: %v\n", path, err)
+		return
+	}
+	sess, manifest, bundled, err := readBundle(data)
+	if err != nil {
+		fmt.Printf("Error reading bundle `%s`: %v\n", path, err)
+		return
+	}
+	resumeSession(sess, config)
+	config.MaxHistoryMessages = bundled.MaxHistoryMessages
+	config.Temperature = bundled.Temperature
+	config.Seed = bundled.Seed
+	config.Stop = bundled.Stop
+	config.MaxTokens = bundled.MaxTokens
+	if err := session.Write(sess); err != nil {
+		fmt.Printf("Error archiving session: %v\n", err)
+	}
+	fmt.Printf("Imported `%s` (%d messages, %s)\n", sess.DisplayName(), len(history.Messages), config.Model)
+	if len(manifest.EmbeddedFiles) > 0 {
+		fmt.Printf("Embedded files: %v\n", manifest.EmbeddedFiles)
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gpt/tools"
+)
+
+var calculateParameters = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"expression": {"type": "string", "description": "e.g. (1.5 + 2) * sqrt(16) / 3^2, supports + - * / % ^, parentheses, pi, e and sqrt, abs, exp, ln, log (base 10), log2, sin, cos, tan, asin, acos, atan, floor, ceil, round, min, max"}
+	},
+	"required": ["expression"]
+}`)
+
+func init() {
+	tools.Register("calculate", tools.Tool{
+		Description: "Evaluates a math expression exactly. Use it for any arithmetic instead of computing in your head.",
+		Parameters:  calculateParameters,
+		Run:         calculateTool,
+	})
+}
+
+var calculatorFunctions = map[string]func(args []float64) (float64, error){
+	"sqrt":  unary(math.Sqrt),
+	"abs":   unary(math.Abs),
+	"exp":   unary(math.Exp),
+	"ln":    unary(math.Log),
+	"log":   unary(math.Log10),
+	"log2":  unary(math.Log2),
+	"sin":   unary(math.Sin),
+	"cos":   unary(math.Cos),
+	"tan":   unary(math.Tan),
+	"asin":  unary(math.Asin),
+	"acos":  unary(math.Acos),
+	"atan":  unary(math.Atan),
+	"floor": unary(math.Floor),
+	"ceil":  unary(math.Ceil),
+	"round": unary(math.Round),
+	"min":   variadic(math.Min),
+	"max":   variadic(math.Max),
+}
+
+var calculatorConstants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+func unary(f func(float64) float64) func([]float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		return f(args[0]), nil
+	}
+}
+
+func variadic(f func(float64, float64) float64) func([]float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, fmt.Errorf("expected at least 1 argument")
+		}
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = f(result, arg)
+		}
+		return result, nil
+	}
+}
+
+func calculateTool(args string) (string, error) {
+	var params struct {
+		Expression string `json:"expression"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return "", fmt.Errorf("invalid arguments: %v", err)
+	}
+	result, err := evaluate(params.Expression)
+	if err != nil {
+		return "", err
+	}
+	return formatNumber(result), nil
+}
+
+// Rounds to 15 significant digits to hide floating point noise, e.g.
+// 0.1 + 0.2, and avoids exponents for usual magnitudes
+func formatNumber(f float64) string {
+	f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'g', 15, 64), 64)
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e15) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Recursive descent parser, from lowest to highest precedence:
+//
+//	expression = term {("+" | "-") term}
+//	term       = unary {("*" | "/" | "%") unary}
+//	unary      = ("-" | "+") unary | power
+//	power      = primary [("^" | "**") unary]
+//	primary    = number | constant | function "(" expression {"," expression} ")" | "(" expression ")"
+type calculator struct {
+	input string
+	pos   int
+}
+
+func evaluate(expression string) (float64, error) {
+	c := &calculator{input: expression}
+	result, err := c.expression()
+	if err != nil {
+		return 0, err
+	}
+	c.skipSpaces()
+	if c.pos < len(c.input) {
+		return 0, fmt.Errorf("unexpected `%s` at position %d", c.input[c.pos:], c.pos+1)
+	}
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, fmt.Errorf("the result is not a finite number")
+	}
+	return result, nil
+}
+
+func (c *calculator) skipSpaces() {
+	for c.pos < len(c.input) && c.input[c.pos] == ' ' {
+		c.pos++
+	}
+}
+
+// Consumes the token if it comes next
+func (c *calculator) accept(token string) bool {
+	c.skipSpaces()
+	if strings.HasPrefix(c.input[c.pos:], token) {
+		c.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (c *calculator) expression() (float64, error) {
+	result, err := c.term()
+	for err == nil {
+		var right float64
+		switch {
+		case c.accept("+"):
+			right, err = c.term()
+			result += right
+		case c.accept("-"):
+			right, err = c.term()
+			result -= right
+		default:
+			return result, nil
+		}
+	}
+	return 0, err
+}
+
+func (c *calculator) term() (float64, error) {
+	result, err := c.unary()
+	for err == nil {
+		var right float64
+		switch {
+		case c.accept("*"):
+			right, err = c.unary()
+			result *= right
+		case c.accept("/"):
+			if right, err = c.unary(); err == nil && right == 0 {
+				err = fmt.Errorf("division by zero")
+			}
+			result /= right
+		case c.accept("%"):
+			if right, err = c.unary(); err == nil && right == 0 {
+				err = fmt.Errorf("division by zero")
+			}
+			result = math.Mod(result, right)
+		default:
+			return result, nil
+		}
+	}
+	return 0, err
+}
+
+func (c *calculator) unary() (float64, error) {
+	if c.accept("-") {
+		result, err := c.unary()
+		return -result, err
+	}
+	if c.accept("+") {
+		return c.unary()
+	}
+	return c.power()
+}
+
+// Right associative, and binds tighter than a unary minus on its left
+func (c *calculator) power() (float64, error) {
+	base, err := c.primary()
+	if err != nil {
+		return 0, err
+	}
+	if c.accept("^") || c.accept("**") {
+		exponent, err := c.unary()
+		if err != nil {
+			return 0, err
+		}
+		return math.Pow(base, exponent), nil
+	}
+	return base, nil
+}
+
+func (c *calculator) primary() (float64, error) {
+	c.skipSpaces()
+	if c.accept("(") {
+		result, err := c.expression()
+		if err != nil {
+			return 0, err
+		}
+		if !c.accept(")") {
+			return 0, fmt.Errorf("missing `)` at position %d", c.pos+1)
+		}
+		return result, nil
+	}
+
+	start := c.pos
+	for c.pos < len(c.input) && (unicode.IsDigit(rune(c.input[c.pos])) || c.input[c.pos] == '.') {
+		c.pos++
+	}
+	if c.pos > start {
+		// Scientific notation, e.g. 1.5e-3
+		if c.pos < len(c.input) && (c.input[c.pos] == 'e' || c.input[c.pos] == 'E') {
+			end := c.pos + 1
+			if end < len(c.input) && (c.input[end] == '-' || c.input[end] == '+') {
+				end++
+			}
+			if end < len(c.input) && unicode.IsDigit(rune(c.input[end])) {
+				for c.pos = end; c.pos < len(c.input) && unicode.IsDigit(rune(c.input[c.pos])); c.pos++ {
+				}
+			}
+		}
+		return strconv.ParseFloat(c.input[start:c.pos], 64)
+	}
+
+	for c.pos < len(c.input) && (unicode.IsLetter(rune(c.input[c.pos])) || unicode.IsDigit(rune(c.input[c.pos]))) {
+		c.pos++
+	}
+	name := strings.ToLower(c.input[start:c.pos])
+	if name == "" {
+		if c.pos == len(c.input) {
+			return 0, fmt.Errorf("unexpected end of expression")
+		}
+		return 0, fmt.Errorf("unexpected `%c` at position %d", c.input[c.pos], c.pos+1)
+	}
+	if value, ok := calculatorConstants[name]; ok {
+		return value, nil
+	}
+	function, ok := calculatorFunctions[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown name `%s`", name)
+	}
+	if !c.accept("(") {
+		return 0, fmt.Errorf("expected `(` after `%s`", name)
+	}
+	args := []float64{}
+	for {
+		arg, err := c.expression()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, arg)
+		if c.accept(")") {
+			break
+		}
+		if !c.accept(",") {
+			return 0, fmt.Errorf("missing `)` at position %d", c.pos+1)
+		}
+	}
+	result, err := function(args)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/provider"
+	"gpt/session"
+)
+
+func buildMessages(config config.Config) []openai.ChatCompletionMessage {
+	refreshWatchedItems()
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: systemPrompt(config)},
+	}
+	for _, msg := range history.Messages {
+		messages = append(messages, msg.ChatMessage())
+	}
+	return messages
+}
+
+// Adds a message to both the history and the session transcript
+func appendMessage(msg session.Message) {
+	history.Append(msg)
+	currentSession.Messages = append(currentSession.Messages, msg)
+}
+
+// Removes the last message added with appendMessage
+func dropLastMessage() {
+	history.DropLast()
+	currentSession.Messages = currentSession.Messages[:len(currentSession.Messages)-1]
+}
+
+func init() {
+	chat.OnUsage = recordUsage
+	// Covers the one-shot commands, such as /translate or `gpt schedule`
+	chat.Filter = filterPrompt
+}
+
+// Requests can run concurrently, e.g. during a review
+var usageMutex sync.Mutex
+
+func recordUsage(stats chat.ResponseStats) {
+	if stats.Cached {
+		return
+	}
+	chat.ObserveRequest(stats)
+	usageMutex.Lock()
+	defer usageMutex.Unlock()
+	sessionStats.Record(stats)
+	err := appendUsage(UsageEntry{
+		Time:             time.Now(),
+		Model:            stats.Model,
+		PromptTokens:     stats.PromptTokens,
+		CompletionTokens: stats.CompletionTokens,
+		Cost:             provider.ComputeCost(stats.Model, stats.PromptTokens, stats.CompletionTokens),
+	})
+	if err != nil {
+		fmt.Printf("Error writing usage ledger: %v\n", err)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+
+	"gpt/i18n"
+	"gpt/session"
+)
+
+// In clipboard watch mode, new clipboard content is offered as context
+// before sending a message
+var (
+	clipboardWatch bool
+	lastClipboard  string
+)
+
+func toggleClipboardWatch() {
+	clipboardWatch = !clipboardWatch
+	if !clipboardWatch {
+		fmt.Println("Clipboard watch disabled")
+		return
+	}
+	// Only what is copied from now on is offered
+	content, err := clipboard.ReadAll()
+	if err != nil {
+		clipboardWatch = false
+		fmt.Printf("Error reading clipboard: %v\n", err)
+		return
+	}
+	lastClipboard = content
+	fmt.Println("Clipboard watch enabled: what you copy will be offered as context for your next message")
+}
+
+// Offers to embed the clipboard if it changed since the last message
+func offerClipboard() {
+	if !clipboardWatch {
+		return
+	}
+	content, err := clipboard.ReadAll()
+	if err != nil || content == lastClipboard {
+		return
+	}
+	lastClipboard = content
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	preview := lines[0]
+	if len(preview) > 60 {
+		preview = preview[:60] + "..."
+	}
+	if len(lines) > 1 {
+		preview += fmt.Sprintf(" (%d lines)", len(lines))
+	}
+	fmt.Printf("Clipboard: %s\n", preview)
+	if !confirm(i18n.T("Add it to the context?")) {
+		return
+	}
+	addContextItem(session.ContextItem{Name: "clipboard", Kind: session.CONTEXT_CLIPBOARD, Content: content})
+	fmt.Println("Added clipboard content to system prompt")
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/render"
+	"gpt/tools"
+)
+
+const (
+	DEFAULT_CODE_TIMEOUT      = 10
+	DEFAULT_CODE_MEMORY_LIMIT = 256
+	MAX_CODE_OUTPUT           = 10000
+)
+
+var executeCodeParameters = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"language": {"type": "string", "enum": ["python", "go"]},
+		"code": {"type": "string", "description": "A complete program, Go code must be a main package. Print the results to stdout."}
+	},
+	"required": ["language", "code"]
+}`)
+
+// Adds the execute_code tool when CodeExecution is enabled
+func enableCodeExecution(config config.Config) {
+	if !config.CodeExecution {
+		return
+	}
+	tools.Register("execute_code", tools.Tool{
+		Description: "Runs a Python or Go program in a sandbox and returns its output. Use it to compute things rather than guessing.",
+		Parameters:  executeCodeParameters,
+		Run: func(args string) (string, error) {
+			return executeCode(config, args)
+		},
+	})
+}
+
+func executeCode(config config.Config, args string) (string, error) {
+	var params struct {
+		Language string `json:"language"`
+		Code     string `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return "", fmt.Errorf("invalid arguments: %v", err)
+	}
+
+	timeout, memory := config.CodeTimeout, config.CodeMemoryLimit
+	if timeout <= 0 {
+		timeout = DEFAULT_CODE_TIMEOUT
+	}
+	if memory <= 0 {
+		memory = DEFAULT_CODE_MEMORY_LIMIT
+	}
+
+	if params.Language != "python" && params.Language != "go" {
+		return "", fmt.Errorf("unsupported language `%s`, expected python or go", params.Language)
+	}
+	// Servers and bots only have the tool when ServerTools is set
+	if console != nil {
+		fmt.Println()
+		render.Markdown(config, fmt.Sprintf("```%s\n%s\n```", params.Language, params.Code))
+		if !confirm(i18n.T("Run this program?")) {
+			return "", fmt.Errorf("the user refused to run the program")
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "gpt-code-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	// The child gets a minimal environment, without the API key
+	env := []string{"PATH=" + os.Getenv("PATH"), "HOME=" + dir, "TMPDIR=" + dir}
+	writable := []string{dir}
+
+	// The memory limit is a hard limit of the data segment rather than the
+	// address space, which the Go runtime reserves a lot of. It only applies
+	// to the program, not to the Go compiler.
+	limit := fmt.Sprintf("ulimit -d %d", memory*1024)
+	var file, script string
+	// Only the compiler can write to the shared Go build cache, the program
+	// could otherwise poison the entries trusted by the builds of the user
+	buildWritable := writable
+	switch params.Language {
+	case "python":
+		file = "main.py"
+		script = limit + " && exec python3 main.py"
+	case "go":
+		file = "main.go"
+		script = limit + " && exec ./main"
+		cache := filepath.Join(dir, "go-build")
+		if dir, err := os.UserCacheDir(); err == nil {
+			cache = filepath.Join(dir, "go-build")
+			if err := os.MkdirAll(cache, 0755); err != nil {
+				return "", err
+			}
+			buildWritable = append(slices.Clone(writable), cache)
+		}
+		env = append(env, "GOCACHE="+cache, "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local")
+	}
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(params.Code), 0600); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+	var output []byte
+	if params.Language == "go" {
+		build, err := codeCommand(ctx, config, dir, env, buildWritable, "go build -o main main.go")
+		if err != nil {
+			return "", err
+		}
+		output, err = build.CombinedOutput()
+		if err != nil {
+			return codeResult(ctx, timeout, output, err), nil
+		}
+	}
+	cmd, err := codeCommand(ctx, config, dir, env, writable, script)
+	if err != nil {
+		return "", err
+	}
+	runOutput, err := cmd.CombinedOutput()
+	return codeResult(ctx, timeout, append(output, runOutput...), err), nil
+}
+
+// Runs a shell script in dir, where only the writable directories can be
+// written to
+func codeCommand(ctx context.Context, config config.Config, dir string, env []string, writable []string, script string) (*exec.Cmd, error) {
+	cmd, err := sandboxCommand(ctx, writable, config.CodeNetwork, []string{"sh", "-c", script})
+	if err != nil {
+		return nil, err
+	}
+	cmd.Dir = dir
+	cmd.Env = env
+	return cmd, nil
+}
+
+// The output of the program, along with the reason it failed if it did
+func codeResult(ctx context.Context, timeout int, output []byte, err error) string {
+	result := string(output)
+	if len(result) > MAX_CODE_OUTPUT {
+		result = truncate(result, MAX_CODE_OUTPUT) + "\n[output truncated]"
+	}
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result += fmt.Sprintf("\n[killed after %ds]", timeout)
+	case err != nil:
+		result += fmt.Sprintf("\n[%v]", err)
+	}
+	return strings.TrimSpace(result)
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+func sandboxCommand(ctx context.Context, writable []string, network bool, command []string) (*exec.Cmd, error) {
+	return nil, fmt.Errorf("code execution is only supported on Unix systems")
+}
@@ -0,0 +1,85 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"syscall"
+)
+
+// Makes every mount read-only, then binds the directories given before `--`
+// writable and runs the command. Meant for a new mount namespace.
+const READ_ONLY_SCRIPT = `
+while read -r _ m _ o _; do
+	f=remount,bind,ro
+	for flag in nosuid nodev noexec; do
+		case ",$o," in *,$flag,*) f=$f,$flag ;; esac
+	done
+	mount -o "$f" "$m" 2>/dev/null
+done < /proc/self/mounts
+while [ "$1" != "--" ]; do
+	mount --bind "$1" "$1" && mount -o remount,bind,rw "$1" || exit 125
+	shift
+done
+shift
+# The working directory was entered before it was bound
+cd "$PWD" && exec "$@"`
+
+// Wraps the command so that it can only write to the writable directories
+// and, unless network is true, can't access the network
+func sandboxCommand(ctx context.Context, writable []string, network bool, command []string) (*exec.Cmd, error) {
+	for i, dir := range writable {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			writable[i] = real
+		}
+	}
+	switch runtime.GOOS {
+	case "linux":
+		if _, err := exec.LookPath("bwrap"); err == nil {
+			args := []string{"bwrap", "--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--die-with-parent"}
+			for _, dir := range writable {
+				args = append(args, "--bind", dir, dir)
+			}
+			if !network {
+				args = append(args, "--unshare-net")
+			}
+			command = append(append(args, "--"), command...)
+			break
+		}
+		if _, err := exec.LookPath("unshare"); err != nil {
+			return nil, fmt.Errorf("can't sandbox the program, install bubblewrap or unshare")
+		}
+		// A new user namespace allows creating mount and network namespaces
+		// without root
+		args := []string{"unshare", "--user", "--map-root-user", "--mount"}
+		if !network {
+			args = append(args, "--net")
+		}
+		args = append(append(args, "sh", "-c", READ_ONLY_SCRIPT, "sh"), writable...)
+		command = append(append(args, "--"), command...)
+	case "darwin":
+		profile := "(version 1)(allow default)(deny file-write*)(allow file-write* (regex #\"^/dev/\")"
+		for _, dir := range writable {
+			profile += fmt.Sprintf(" (subpath %q)", dir)
+		}
+		profile += ")"
+		if !network {
+			profile += "(deny network*)"
+		}
+		command = append([]string{"sandbox-exec", "-p", profile}, command...)
+	default:
+		return nil, fmt.Errorf("can't sandbox the program on %s", runtime.GOOS)
+	}
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	// Kill the whole process group on timeout, e.g. the binary of `go run`
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	return cmd, nil
+}
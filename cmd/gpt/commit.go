@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/i18n"
+)
+
+const COMMIT_PROMPT = "Write a git commit message for the following staged changes. " +
+	"Use a short imperative subject line of at most 72 characters, then a blank line and a body explaining what changed and why, wrapped at 72 characters. " +
+	"Omit the body for trivial changes. Follow the style of the recent commit subjects if there are any. Answer with the message only, without code fences."
+
+// Diffs bigger than this are cut, the stat still lists every file
+const MAX_DIFF_SIZE = SUMMARY_CHUNK_SIZE
+
+// Runs git and returns its output, with stderr in the error
+func git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	out, err := cmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return string(out), err
+}
+
+// Runs git attached to the terminal, for interactive commands
+func gitInteractive(args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = terminalStdout()
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func countLines(s string) int {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+	return strings.Count(s, "\n") + 1
+}
+
+func truncateDiff(diff string) string {
+	if len(diff) <= MAX_DIFF_SIZE {
+		return diff
+	}
+	return truncate(diff, MAX_DIFF_SIZE) + "\n[diff truncated]"
+}
+
+// Handles `/commit`: offers to stage changes, generates a message for the
+// staged ones, then commits after confirmation
+func commitCommand(client *openai.Client, config config.Config) {
+	if _, err := git("rev-parse", "--is-inside-work-tree"); err != nil {
+		fmt.Println("Error: not in a git repository")
+		return
+	}
+	staged, err := git("diff", "--cached", "--name-only")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	unstaged, _ := git("diff", "--name-only")
+	untracked, _ := git("ls-files", "--others", "--exclude-standard")
+	fmt.Printf("%d staged, %d unstaged and %d untracked files\n", countLines(staged), countLines(unstaged), countLines(untracked))
+
+	if countLines(unstaged)+countLines(untracked) > 0 {
+		answer, _ := ask(i18n.T("Stage changes? [a]ll, [p]ick hunks, [N]o "))
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "a", "all":
+			if _, err := git("add", "-A"); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+		case "p", "pick":
+			// Untracked files have no hunks to pick
+			if err := gitInteractive("add", "-p"); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+		}
+	}
+
+	stat, err := git("diff", "--cached", "--stat")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if strings.TrimSpace(stat) == "" {
+		fmt.Println("Nothing staged to commit")
+		return
+	}
+	diff, err := git("diff", "--cached")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if !checkBudget(config) {
+		return
+	}
+
+	recent, _ := git("log", "-10", "--format=%s")
+	input := fmt.Sprintf("Recent commit subjects:\n%s\nStat:\n%s\nDiff:\n%s", recent, stat, truncateDiff(diff))
+	message, err := chat.Complete(client, config.Model, COMMIT_PROMPT, input)
+	if err != nil {
+		fmt.Printf("Error generating commit message: %v\n", err)
+		return
+	}
+	fmt.Printf("\n%s\n%s\n\n", stat, message)
+
+	file, err := os.CreateTemp("", "gpt-commit-*.txt")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer os.Remove(file.Name())
+	file.WriteString(message + "\n")
+	file.Close()
+
+	answer, _ := ask(i18n.T("[c]ommit, [e]dit then commit, [N]o "))
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "c", "commit":
+		err = gitInteractive("commit", "-F", file.Name())
+	case "e", "edit":
+		err = gitInteractive("commit", "-e", "-F", file.Name())
+	default:
+		fmt.Println("Commit aborted, the changes stay staged")
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/provider"
+	"gpt/render"
+	"gpt/session"
+)
+
+type compareResult struct {
+	Index    int
+	Model    string
+	Response string
+	Stats    chat.ResponseStats
+	Err      error
+}
+
+// Prints the sections of the compared models. One of them streams to the
+// terminal at a time, the others are buffered until it is complete.
+type compareOutput struct {
+	config   config.Config
+	mutex    sync.Mutex
+	live     int
+	buffers  []strings.Builder
+	results  []*compareResult
+	finished []bool
+}
+
+func newCompareOutput(config config.Config) *compareOutput {
+	n := len(config.CompareModels)
+	return &compareOutput{config: config, live: -1, buffers: make([]strings.Builder, n), results: make([]*compareResult, n), finished: make([]bool, n)}
+}
+
+// Models compared more than once are numbered
+func (o *compareOutput) label(i int) string {
+	model := o.config.CompareModels[i]
+	same := slices.DeleteFunc(slices.Clone(o.config.CompareModels), func(m string) bool { return m != model })
+	if len(same) > 1 {
+		return fmt.Sprintf("%s #%d", model, i+1)
+	}
+	return model
+}
+
+func (o *compareOutput) chunk(i int, chunk string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	// Screen readers get each response at once
+	if o.live == -1 && !render.Accessible {
+		o.live = i
+		fmt.Printf("\n--- %s ---\n%s", o.label(i), o.buffers[i].String())
+	}
+	if o.live == i {
+		fmt.Print(chunk)
+	}
+	o.buffers[i].WriteString(chunk)
+}
+
+func (o *compareOutput) finish(result compareResult) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	i := result.Index
+	o.results[i] = &result
+	switch o.live {
+	case i:
+		o.printEnd(i)
+		o.live = -1
+	case -1:
+		o.printSection(i)
+	default:
+		// Printed once the live section is complete
+		return
+	}
+	for j := range o.results {
+		if o.results[j] != nil && !o.finished[j] {
+			o.printSection(j)
+		}
+	}
+	// The next response in progress takes over the terminal
+	for j := range o.results {
+		if o.results[j] == nil && o.buffers[j].Len() > 0 && !render.Accessible {
+			o.live = j
+			fmt.Printf("\n--- %s ---\n%s", o.label(j), o.buffers[j].String())
+			return
+		}
+	}
+}
+
+func (o *compareOutput) printSection(i int) {
+	fmt.Printf("\n--- %s ---\n", o.label(i))
+	if o.results[i].Err == nil {
+		fmt.Print(o.buffers[i].String())
+	}
+	o.printEnd(i)
+}
+
+// Ends a section with the error, or the rendered response and its stats
+func (o *compareOutput) printEnd(i int) {
+	o.finished[i] = true
+	result := o.results[i]
+	if result.Err != nil {
+		fmt.Printf("Error: %v\n", result.Err)
+		return
+	}
+	fmt.Println()
+	if o.config.RenderMarkdown && !o.config.Accessible {
+		out, _ := glamour.Render(result.Response, o.config.Theme)
+		fmt.Println("--- Rendered Markdown ---")
+		fmt.Print(out)
+	}
+	if o.config.ShowStats {
+		printResponseStats(result.Stats)
+	}
+}
+
+// Sends the prompt to every model of `CompareModels`, `Concurrency` at a
+// time. The responses stream in their own section, and the successful ones
+// are recorded in the history as a single assistant message.
+func runCompare(client *openai.Client, config config.Config, prompt string) string {
+	appendMessage(session.NewMessage(openai.ChatMessageRoleUser, prompt))
+	sessionStats.CountMessage(openai.ChatMessageRoleUser)
+	messages := buildMessages(config)
+
+	output := newCompareOutput(config)
+	results := make(chan compareResult)
+	go provider.RunPool(provider.Concurrency(config), len(config.CompareModels), func(i int) {
+		model := config.CompareModels[i]
+		response, _, stats, err := chat.StreamCompletion(client, chat.NewRequest(config, model, messages), func(chunk string) {
+			output.chunk(i, chunk)
+		}, nil)
+		results <- compareResult{Index: i, Model: model, Response: response, Stats: stats, Err: err}
+	})
+
+	collected := make([]compareResult, len(config.CompareModels))
+	for range config.CompareModels {
+		result := <-results
+		collected[result.Index] = result
+		output.finish(result)
+		if result.Err == nil {
+			recordUsage(result.Stats)
+			sessionStats.CountMessage(openai.ChatMessageRoleAssistant)
+		}
+	}
+
+	sb := strings.Builder{}
+	responses := []map[string]string{}
+	for i, result := range collected {
+		if result.Err != nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("### Alternative from %s\n\n%s\n\n", output.label(i), result.Response))
+		responses = append(responses, map[string]string{"model": result.Model, "response": result.Response})
+	}
+	// Nothing to answer the prompt with
+	if len(responses) == 0 {
+		dropLastMessage()
+		return ""
+	}
+	combined := strings.TrimSpace(sb.String())
+	notifyWebhooks(config, EVENT_COMPARE, map[string]any{"prompt": prompt, "responses": responses})
+	msg := session.NewMessage(openai.ChatMessageRoleAssistant, combined)
+	msg.Model = strings.Join(config.CompareModels, ",")
+	appendMessage(msg)
+	return combined
+}
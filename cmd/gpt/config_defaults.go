@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gpt/commands"
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/render"
+)
+
+// Handles `/config path`: prints the config files, the later ones override
+// the earlier ones
+func printConfigPath() {
+	for _, path := range configFiles {
+		fmt.Println(path)
+	}
+	if _, ok := config.FindProject("."); !ok {
+		fmt.Printf("No project config, create `%s.toml` to override the config in this directory\n", config.PROJECT_NAME)
+	}
+}
+
+// Handles `/config reset [Field] [--persist]`: resets one field, or the whole
+// config after a confirmation, to the built-in defaults
+func resetConfig(cfg *config.Config, field string, persist bool) {
+	defaults := config.Default()
+	if field == "" {
+		if !confirm(i18n.T("Reset every field of the config to its default?")) {
+			return
+		}
+		*cfg = defaults
+		if persist {
+			persistConfigFields(*cfg, configFields()...)
+			fmt.Println("Config reset to the defaults and saved")
+		} else {
+			fmt.Println("Config reset to the defaults for this session, use `--persist` to save it")
+		}
+		return
+	}
+
+	cfgVal := reflect.ValueOf(cfg).Elem()
+	for i := range cfgVal.NumField() {
+		name := cfgVal.Type().Field(i).Name
+		if strings.EqualFold(name, field) {
+			cfgVal.Field(i).Set(reflect.ValueOf(defaults).Field(i))
+			if persist {
+				persistConfigFields(*cfg, name)
+				fmt.Printf("Config reset and saved: %s = %s\n", name, formatFieldValue(cfgVal.Field(i)))
+			} else {
+				fmt.Printf("Config reset for this session: %s = %s, use `--persist` to save it\n", name, formatFieldValue(cfgVal.Field(i)))
+			}
+			return
+		}
+	}
+	if suggestion, ok := commands.Closest(field, configFields()); ok {
+		fmt.Print(i18n.T("Unknown config field: %s, did you mean `%s`?\n", field, suggestion))
+	} else {
+		fmt.Print(i18n.T("Unknown config field: %s\n", field))
+	}
+}
+
+// Empty lists and tables are the same as missing ones
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
+
+// Handles `/config diff`: lists the fields that differ from the defaults
+func printConfigDiff(cfg config.Config) {
+	cfgVal, defaultsVal := reflect.ValueOf(maskSecrets(cfg)), reflect.ValueOf(config.Default())
+	rows := [][]string{}
+	for i := range cfgVal.NumField() {
+		current, def := cfgVal.Field(i), defaultsVal.Field(i)
+		if reflect.DeepEqual(current.Interface(), def.Interface()) || isEmptyValue(current) && isEmptyValue(def) {
+			continue
+		}
+		rows = append(rows, []string{cfgVal.Type().Field(i).Name, formatFieldValue(current), formatFieldValue(def)})
+	}
+	if len(rows) == 0 {
+		fmt.Println("The config only has default values")
+		return
+	}
+	render.Table([]string{"Field", "Value", "Default"}, rows)
+}
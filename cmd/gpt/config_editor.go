@@ -0,0 +1,344 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/chzyer/readline"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/commands"
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/provider"
+	"gpt/render"
+)
+
+var (
+	editorTitleStyle = lipgloss.NewStyle().Bold(true)
+	editorErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
+// Values offered for a config field. Free fields accept other values too, e.g.
+// models missing from the pricing table.
+type fieldOptions struct {
+	values func() []string
+	free   bool
+}
+
+var configFieldOptions = map[string]fieldOptions{
+	"Model": {values: func() []string { return slices.Sorted(maps.Keys(provider.Prices)) }, free: true},
+	"Theme": {values: func() []string {
+		return []string{styles.AutoStyle, styles.DarkStyle, styles.LightStyle, styles.DraculaStyle, styles.TokyoNightStyle, styles.PinkStyle, styles.AsciiStyle, styles.NoTTYStyle}
+	}},
+	"Language":         {values: func() []string { return append([]string{""}, append(i18n.Languages(), i18n.AUTO)...) }},
+	"DiffStyle":        {values: func() []string { return []string{render.DIFF_UNIFIED, render.DIFF_SIDE_BY_SIDE} }},
+	"Moderation":       {values: func() []string { return []string{"", MODERATE_PROMPTS, MODERATE_RESPONSES, MODERATE_ALL} }},
+	"ModerationAction": {values: func() []string { return []string{MODERATION_WARN, MODERATION_BLOCK} }},
+	"CopyFormat":       {values: func() []string { return append([]string{""}, copyFormats()...) }},
+	"ImageSize": {values: func() []string {
+		return []string{openai.CreateImageSize256x256, openai.CreateImageSize512x512, openai.CreateImageSize1024x1024, openai.CreateImageSize1792x1024, openai.CreateImageSize1024x1792}
+	}},
+	"ImageQuality": {values: func() []string { return []string{openai.CreateImageQualityStandard, openai.CreateImageQualityHD} }},
+	"ImagePreview": {values: func() []string {
+		return []string{PREVIEW_AUTO, PREVIEW_KITTY, PREVIEW_ITERM, PREVIEW_SIXEL, PREVIEW_OPEN, PREVIEW_NONE}
+	}},
+	"Voice": {values: func() []string {
+		voices := []openai.SpeechVoice{openai.VoiceAlloy, openai.VoiceEcho, openai.VoiceFable, openai.VoiceOnyx, openai.VoiceNova, openai.VoiceShimmer}
+		values := []string{}
+		for _, voice := range voices {
+			values = append(values, string(voice))
+		}
+		return values
+	}},
+}
+
+var durationType = reflect.TypeOf(config.Duration(0))
+
+// Scalars and lists of strings are edited, tables are left to the config file
+func editableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+func formatFieldValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return strconv.Quote(value.String())
+	case reflect.Slice, reflect.Map:
+		if list, ok := value.Interface().([]string); ok {
+			quoted := make([]string, len(list))
+			for i, item := range list {
+				quoted[i] = strconv.Quote(item)
+			}
+			return "[" + strings.Join(quoted, ", ") + "]"
+		}
+		if value.Len() == 1 {
+			return "1 item"
+		}
+		return fmt.Sprintf("%d items", value.Len())
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+// Parses the values typed for a field, checking them against its options.
+// Lists take any number of values, empty ones are dropped so that `""` clears
+// a list, and words are joined for the other fields.
+func parseFieldValue(field reflect.StructField, values []string) (reflect.Value, error) {
+	if field.Type.Kind() == reflect.Slice {
+		list := []string{}
+		for _, value := range values {
+			if value != "" {
+				list = append(list, value)
+			}
+		}
+		if field.Name == "Stop" && len(list) > MAX_STOP_SEQUENCES {
+			return reflect.Value{}, fmt.Errorf("at most %d stop sequences are supported", MAX_STOP_SEQUENCES)
+		}
+		return reflect.ValueOf(list), nil
+	}
+
+	text := strings.TrimSpace(strings.Join(values, " "))
+	if field.Type == durationType {
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("`%s` is not a duration, e.g. 30s or 2m", text)
+		}
+		return reflect.ValueOf(config.Duration(d)), nil
+	}
+	switch field.Type.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("`%s` is not a boolean, expected true or false", text)
+		}
+		return reflect.ValueOf(b), nil
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("`%s` is not an integer", text)
+		}
+		return reflect.ValueOf(n), nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("`%s` is not a number", text)
+		}
+		return reflect.ValueOf(f), nil
+	}
+
+	name := field.Name
+	if name == "CommandPrefix" && len(text) != 1 {
+		return reflect.Value{}, fmt.Errorf("the command prefix must be a single character")
+	}
+	if options, ok := configFieldOptions[name]; ok && !options.free && !slices.Contains(options.values(), text) {
+		values := options.values()
+		if suggestion, ok := commands.Closest(text, values); ok {
+			return reflect.Value{}, fmt.Errorf("`%s` is not a valid %s, did you mean `%s`?", text, name, suggestion)
+		}
+		return reflect.Value{}, fmt.Errorf("`%s` is not a valid %s, expected one of %s", text, name, quoteAll(values))
+	}
+	return reflect.ValueOf(text), nil
+}
+
+func printConfigForm(edited config.Config, saved config.Config, message string) {
+	// Clears the screen, the form is redrawn after every change. Screen readers
+	// get the form again below instead.
+	if !render.Accessible {
+		fmt.Print("\033[H\033[2J")
+	}
+	fmt.Println(editorTitleStyle.Render(fmt.Sprintf("Editing `%s`", configFile)))
+
+	rows := [][]string{}
+	editedVal, savedVal := reflect.ValueOf(edited), reflect.ValueOf(saved)
+	shownVal := reflect.ValueOf(maskSecrets(edited))
+	for i := range editedVal.NumField() {
+		field := editedVal.Type().Field(i)
+		value := formatFieldValue(shownVal.Field(i))
+		if !reflect.DeepEqual(editedVal.Field(i).Interface(), savedVal.Field(i).Interface()) {
+			value += " *"
+		}
+		options := ""
+		if !editableType(field.Type) {
+			options = "edit in the config file"
+		} else if field.Type.Kind() == reflect.Bool {
+			options = "true, false"
+		} else if field.Type.Kind() == reflect.Slice {
+			options = "values separated by spaces, quoted if needed"
+		} else if fieldOptions, ok := configFieldOptions[field.Name]; ok {
+			values := fieldOptions.values()
+			for i, value := range values {
+				if value == "" {
+					values[i] = `""`
+				}
+			}
+			options = strings.Join(values, ", ")
+		}
+		rows = append(rows, []string{strconv.Itoa(i + 1), field.Name, field.Type.String(), value, options})
+	}
+	render.Table([]string{"#", "Field", "Type", "Value", "Options"}, rows)
+
+	fmt.Println()
+	if message != "" {
+		fmt.Println(editorErrorStyle.Render(message))
+	}
+	fmt.Println("Type the number or name of a field to edit it (booleans are toggled), `apply` to use the changes in this session, `save` to also write them to the config file or `quit` to leave")
+}
+
+// Returns the index of the field typed in the editor
+func findConfigField(configType reflect.Type, input string) (int, error) {
+	if n, err := strconv.Atoi(input); err == nil {
+		if n < 1 || n > configType.NumField() {
+			return 0, fmt.Errorf("there is no field %d", n)
+		}
+		return n - 1, nil
+	}
+	for i := range configType.NumField() {
+		if strings.EqualFold(configType.Field(i).Name, input) {
+			return i, nil
+		}
+	}
+	if suggestion, ok := commands.Closest(input, configFields()); ok {
+		return 0, fmt.Errorf("unknown config field: %s, did you mean `%s`?", input, suggestion)
+	}
+	return 0, fmt.Errorf("unknown config field: %s", input)
+}
+
+// Asks for the new value of a field until it is valid, Tab completes its
+// options. Ctrl-C cancels the edit.
+func editConfigField(edited *config.Config, saved config.Config, index int) {
+	field := reflect.TypeOf(*edited).Field(index)
+	value := reflect.ValueOf(edited).Elem().Field(index)
+	if field.Type.Kind() == reflect.Bool {
+		value.SetBool(!value.Bool())
+		return
+	}
+
+	if options, ok := configFieldOptions[field.Name]; ok {
+		items := []readline.PrefixCompleterInterface{}
+		for _, option := range options.values() {
+			items = append(items, readline.PcItem(option))
+		}
+		completer := console.Config.AutoComplete
+		console.Config.AutoComplete = readline.NewPrefixCompleter(items...)
+		defer func() { console.Config.AutoComplete = completer }()
+	}
+
+	current := fmt.Sprint(value.Interface())
+	if list, ok := value.Interface().([]string); ok {
+		current = quoteAll(list)
+	}
+	// Secrets are typed masked, kept out of the REPL history, and kept when
+	// nothing is typed
+	secret := slices.Contains(secretFields, field.Name)
+	if secret {
+		current = ""
+		mask, saveHistory := console.Config.MaskRune, !console.Config.DisableAutoSaveHistory
+		console.SetMaskRune('*')
+		console.Config.EnableMask = true
+		console.Config.DisableAutoSaveHistory = true
+		defer func() {
+			console.SetMaskRune(mask)
+			console.Config.EnableMask = false
+			console.Config.DisableAutoSaveHistory = !saveHistory
+		}()
+	}
+	for {
+		text, ok := askWithDefault(field.Name+"> ", current)
+		if !ok || secret && text == "" {
+			return
+		}
+		values := []string{text}
+		var err error
+		if field.Type.Kind() == reflect.Slice {
+			values, err = splitArgs(text)
+		}
+		var parsed reflect.Value
+		if err == nil {
+			parsed, err = parseFieldValue(field, values)
+		}
+		if err == nil {
+			value.Set(parsed.Convert(field.Type))
+			return
+		}
+		printConfigForm(*edited, saved, fmt.Sprintf("Error: %v", err))
+		current = text
+	}
+}
+
+func changedConfigFields(before config.Config, after config.Config) []string {
+	changed := []string{}
+	beforeVal, afterVal := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := range beforeVal.NumField() {
+		if !reflect.DeepEqual(beforeVal.Field(i).Interface(), afterVal.Field(i).Interface()) {
+			changed = append(changed, beforeVal.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
+
+// Handles `/config edit`: edits a copy of the config, which replaces the
+// config of the session on apply, and whose changes are also written to the
+// config file on save
+func editConfig(cfg *config.Config) {
+	if console == nil {
+		fmt.Println("Error: the config editor needs a terminal")
+		return
+	}
+	edited := *cfg
+	message := ""
+	for {
+		printConfigForm(edited, *cfg, message)
+		message = ""
+		input, ok := ask("edit> ")
+		if !ok {
+			input = "quit"
+		}
+		input = strings.TrimSpace(input)
+		switch strings.ToLower(input) {
+		case "":
+			continue
+		case "save", "s":
+			changed := changedConfigFields(*cfg, edited)
+			*cfg = edited
+			if len(changed) > 0 {
+				persistConfigFields(*cfg, changed...)
+			}
+			fmt.Printf("Config saved to `%s`\n", configFile)
+			return
+		case "apply", "a":
+			*cfg = edited
+			fmt.Println("Config updated for this session")
+			return
+		case "quit", "q":
+			if !reflect.DeepEqual(edited, *cfg) && !confirm(i18n.T("Discard the changes?")) {
+				continue
+			}
+			fmt.Println("Config unchanged")
+			return
+		}
+
+		index, err := findConfigField(reflect.TypeOf(edited), input)
+		if err != nil {
+			message = fmt.Sprintf("Error: %v", err)
+			continue
+		}
+		if !editableType(reflect.TypeOf(edited).Field(index).Type) {
+			message = fmt.Sprintf("Error: %s can only be edited in `%s`", reflect.TypeOf(edited).Field(index).Name, configFile)
+			continue
+		}
+		editConfigField(&edited, *cfg, index)
+	}
+}
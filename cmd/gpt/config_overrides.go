@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"gpt/config"
+	"gpt/render"
+)
+
+// The config as written in the config files. Changes made with `/config` only
+// apply to the running session unless they are persisted.
+var fileConfig config.Config
+
+// Writes the given fields of the session config to the config file, leaving the
+// other overrides to the session. The project config is left untouched.
+func persistConfigFields(cfg config.Config, fields ...string) {
+	saved, err := config.Load(configFile)
+	if err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	savedVal, fileVal, cfgVal := reflect.ValueOf(&saved).Elem(), reflect.ValueOf(&fileConfig).Elem(), reflect.ValueOf(cfg)
+	for _, field := range fields {
+		savedVal.FieldByName(field).Set(cfgVal.FieldByName(field))
+		fileVal.FieldByName(field).Set(cfgVal.FieldByName(field))
+	}
+	saveConfig(saved)
+
+	// The project config still overrides the saved values
+	if layered, _, err := config.LoadLayers(configFile, "."); err == nil {
+		for _, field := range fields {
+			if !reflect.DeepEqual(reflect.ValueOf(layered).FieldByName(field).Interface(), cfgVal.FieldByName(field).Interface()) {
+				fmt.Printf("Note: %s is overridden by the project config\n", field)
+			}
+		}
+	}
+}
+
+// Returns the names of the fields of the session config that differ from the
+// config file
+func configOverrides(cfg config.Config) []string {
+	overrides := []string{}
+	fileVal, cfgVal := reflect.ValueOf(fileConfig), reflect.ValueOf(cfg)
+	for i := range cfgVal.NumField() {
+		if !reflect.DeepEqual(cfgVal.Field(i).Interface(), fileVal.Field(i).Interface()) && !(isEmptyValue(cfgVal.Field(i)) && isEmptyValue(fileVal.Field(i))) {
+			overrides = append(overrides, cfgVal.Type().Field(i).Name)
+		}
+	}
+	return overrides
+}
+
+func printConfigOverrides(cfg config.Config) {
+	overrides := configOverrides(cfg)
+	if len(overrides) == 0 {
+		return
+	}
+	fmt.Println("Overridden for this session, use `--persist` to save a change:")
+	rows := [][]string{}
+	for _, field := range overrides {
+		rows = append(rows, []string{field, formatFieldValue(reflect.ValueOf(maskSecrets(cfg)).FieldByName(field)), formatFieldValue(reflect.ValueOf(maskSecrets(fileConfig)).FieldByName(field))})
+	}
+	render.Table([]string{"Field", "Session", "Config files"}, rows)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/chzyer/readline"
+
+	"gpt/i18n"
+)
+
+// The REPL line reader, used to ask questions in the middle of a command.
+// Servers and bots have none: nothing is read from stdin and every question
+// is answered no.
+var console *readline.Instance
+
+func confirm(question string) bool {
+	answer, ok := ask(question + i18n.T(" [y/N] "))
+	return ok && i18n.IsYes(answer)
+}
+
+// Reads lines until Ctrl-D or a line containing only `.`
+func readMultiline(prompt string) (string, bool) {
+	if console == nil {
+		return "", false
+	}
+	defer console.SetPrompt(sessionPrompt())
+	console.SetPrompt(prompt)
+	lines := []string{}
+	for {
+		line, err := console.Readline()
+		if err == readline.ErrInterrupt {
+			return "", false
+		}
+		if err != nil || line == "." {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+func ask(prompt string) (string, bool) {
+	if console == nil {
+		return "", false
+	}
+	defer console.SetPrompt(sessionPrompt())
+	console.SetPrompt(prompt)
+	line, err := console.Readline()
+	if err != nil {
+		return "", false
+	}
+	return line, true
+}
+
+// Like ask, with an answer that can be edited
+func askWithDefault(prompt string, answer string) (string, bool) {
+	if console == nil {
+		return "", false
+	}
+	defer console.SetPrompt(sessionPrompt())
+	console.SetPrompt(prompt)
+	line, err := console.ReadlineWithDefault(answer)
+	if err != nil {
+		return "", false
+	}
+	return line, true
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gpt/config"
+	"gpt/render"
+	"gpt/session"
+)
+
+var contextItems []session.ContextItem
+
+// Embedding a file, running a command or capturing a pane again refreshes it,
+// other items get a unique name
+func addContextItem(item session.ContextItem) {
+	if item.Kind == session.CONTEXT_FILE || item.Kind == session.CONTEXT_COMMAND || item.Kind == session.CONTEXT_PANE {
+		for i, existing := range contextItems {
+			if existing.Name == item.Name {
+				contextItems[i] = item
+				return
+			}
+		}
+	} else {
+		name := item.Name
+		for n := 2; findContextItem(item.Name) != -1; n++ {
+			item.Name = fmt.Sprintf("%s (%d)", name, n)
+		}
+	}
+	contextItems = append(contextItems, item)
+}
+
+func findContextItem(name string) int {
+	for i, item := range contextItems {
+		if item.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func removeContextItem(name string) {
+	idx := findContextItem(name)
+	if idx == -1 {
+		fmt.Printf("Error: `%s` is not embedded\n", name)
+		return
+	}
+	contextItems = append(contextItems[:idx], contextItems[idx+1:]...)
+	fmt.Printf("Removed `%s` from system prompt\n", name)
+}
+
+func contextPrompt() string {
+	sb := strings.Builder{}
+	for _, item := range contextItems {
+		switch item.Kind {
+		case session.CONTEXT_FILE:
+			sb.WriteString(fmt.Sprintf("\nFile `%s`:\n", item.Name))
+		case session.CONTEXT_EXCERPT:
+			sb.WriteString(fmt.Sprintf("\nExcerpt from a previous conversation (%s):\n", item.Name))
+		case session.CONTEXT_PANE:
+			sb.WriteString("\nContent of the user's terminal:\n")
+		case session.CONTEXT_COMMAND:
+			sb.WriteString(fmt.Sprintf("\nOutput of the command `%s`, run by the user:\n", strings.TrimPrefix(item.Name, "!")))
+		default:
+			sb.WriteString(fmt.Sprintf("\nContent of the %s:\n", item.Kind))
+		}
+		sb.WriteString(item.Content)
+	}
+	return sb.String()
+}
+
+func contextNames() []string {
+	names := make([]string, len(contextItems))
+	for i, item := range contextItems {
+		names[i] = item.Name
+	}
+	return names
+}
+
+func printContextItems(config config.Config) {
+	if len(contextItems) == 0 {
+		fmt.Println("Nothing embedded")
+		return
+	}
+	rows := [][]string{}
+	total := 0
+	for _, item := range contextItems {
+		tokens := session.EstimateTokens(item.Content)
+		total += tokens
+		kind := item.Kind
+		if item.Watch {
+			kind += " (watched)"
+		}
+		rows = append(rows, []string{item.Name, kind, "~" + strconv.Itoa(tokens)})
+	}
+	render.Table([]string{"Name", "Kind", "Tokens"}, rows)
+	base := session.EstimateTokens(config.SystemPrompt)
+	fmt.Printf("Total: ~%d tokens embedded, ~%d with the system prompt\n", total, total+base)
+}
+
+func watchContextItem(name string, watch bool) {
+	idx := findContextItem(name)
+	if idx == -1 && watch {
+		if !embedSelection(name) && !embedFile(name) {
+			return
+		}
+		idx = findContextItem(name)
+	}
+	if idx == -1 {
+		fmt.Printf("Error: `%s` is not embedded\n", name)
+		return
+	}
+	item := &contextItems[idx]
+	if item.Path == "" || item.Kind != session.CONTEXT_FILE {
+		fmt.Printf("Error: `%s` is not a file\n", name)
+		return
+	}
+	item.Watch = watch
+	if !watch {
+		fmt.Printf("Stopped watching `%s`\n", name)
+		return
+	}
+	if info, err := os.Stat(item.Path); err == nil {
+		item.ModTime = info.ModTime()
+	}
+	fmt.Printf("Watching `%s`, it will be read again when it changes\n", name)
+}
+
+// Reads the watched files that changed on disk since they were embedded
+func refreshWatchedItems() {
+	for i := range contextItems {
+		item := &contextItems[i]
+		if !item.Watch {
+			continue
+		}
+		info, err := os.Stat(item.Path)
+		if err != nil || info.ModTime().Equal(item.ModTime) {
+			continue
+		}
+		content, err := readContextFile(item.Name)
+		if err != nil {
+			fmt.Printf("Error reloading `%s`: %v\n", item.Name, err)
+			continue
+		}
+		item.Content = content
+		item.ModTime = info.ModTime()
+		fmt.Printf("Reloaded `%s`\n", item.Name)
+	}
+}
+
+func readContextFile(name string) (string, error) {
+	if _, content, ok, err := readSelection(name); ok && !fileExists(name) {
+		return content, err
+	}
+	data, err := os.ReadFile(name)
+	return string(data), err
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/chzyer/readline"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/render"
+)
+
+// Values of CopyFormat
+const (
+	COPY_MARKDOWN = "markdown"
+	COPY_PLAIN    = "plain"
+	COPY_HTML     = "html"
+)
+
+func copyFormats() []string {
+	return []string{COPY_MARKDOWN, COPY_PLAIN, COPY_HTML}
+}
+
+// Handles `/copy [all | user | assistant] [markdown | plain | html]`
+func copyCommand(config config.Config, args []string) {
+	format := config.CopyFormat
+	role, whole := "", false
+	for _, arg := range args {
+		switch {
+		case slices.Contains(copyFormats(), arg):
+			format = arg
+		case arg == "all":
+			whole = true
+		case arg == openai.ChatMessageRoleUser || arg == openai.ChatMessageRoleAssistant:
+			role, whole = arg, true
+		default:
+			fmt.Printf("Error: `%scopy` expects `all`, `user` or `assistant`, and a format: %s\n", config.CommandPrefix, strings.Join(copyFormats(), ", "))
+			return
+		}
+	}
+
+	if !whole {
+		if chatResponse.Len() == 0 {
+			fmt.Println(i18n.T("Nothing to copy!"))
+			return
+		}
+		if err := writeClipboard(chatResponse.String(), format); err != nil {
+			fmt.Printf("Error writing to clipboard: %v\n", err)
+			return
+		}
+		fmt.Println(i18n.T("LLM response copied to clipboard"))
+		return
+	}
+	transcript, count := conversationMarkdown(role)
+	if count == 0 {
+		fmt.Println(i18n.T("Nothing to copy!"))
+		return
+	}
+	if err := writeClipboard(transcript, format); err != nil {
+		fmt.Printf("Error writing to clipboard: %v\n", err)
+		return
+	}
+	fmt.Printf("Copied %d messages to clipboard\n", count)
+}
+
+// The prompts and responses of the conversation under `## User` and
+// `## Assistant` headings, only the ones of role unless it is empty
+func conversationMarkdown(role string) (string, int) {
+	sb := strings.Builder{}
+	count := 0
+	for _, msg := range currentSession.Messages {
+		if msg.Role != openai.ChatMessageRoleUser && msg.Role != openai.ChatMessageRoleAssistant {
+			continue
+		}
+		if role != "" && msg.Role != role {
+			continue
+		}
+		if count > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(fmt.Sprintf("## %s\n\n%s", strings.ToUpper(msg.Role[:1])+msg.Role[1:], strings.TrimSpace(msg.Content)))
+		count++
+	}
+	return sb.String(), count
+}
+
+// Copies Markdown text to the clipboard in format, Markdown when it is empty
+func writeClipboard(text string, format string) error {
+	var err error
+	switch format {
+	case "", COPY_MARKDOWN:
+		err = copyToClipboard(text)
+	case COPY_PLAIN:
+		err = copyToClipboard(render.PlainText(text))
+	case COPY_HTML:
+		var html string
+		if html, err = render.HTML(text); err == nil {
+			err = writeClipboardHTML(html)
+		}
+	default:
+		return fmt.Errorf("unknown copy format `%s`, expected one of %s", format, strings.Join(copyFormats(), ", "))
+	}
+	if err != nil {
+		return err
+	}
+	// The clipboard watch doesn't offer what we copied ourselves
+	if content, err := clipboard.ReadAll(); err == nil {
+		lastClipboard = content
+	}
+	return nil
+}
+
+// Copies text with the system clipboard, or with an OSC 52 escape sequence
+// when there is none, e.g. over SSH: the terminal then sets its clipboard,
+// which also works in tmux and screen
+func copyToClipboard(text string) error {
+	err := clipboard.WriteAll(text)
+	if err == nil || !readline.IsTerminal(int(os.Stderr.Fd())) {
+		return err
+	}
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	// Not logged in the transcript, unlike stdout
+	_, err = seq.WriteTo(os.Stderr)
+	return err
+}
+
+// Rich-text editors only paste HTML as such when the clipboard says it is
+// HTML, which takes osascript, wl-copy or xclip. Otherwise the HTML source is
+// copied as text.
+func writeClipboardHTML(html string) error {
+	var cmd *exec.Cmd
+	switch {
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("set the clipboard to «data HTML%X»", html))
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		cmd = exec.Command("wl-copy", "--type", "text/html")
+	case os.Getenv("DISPLAY") != "":
+		cmd = exec.Command("xclip", "-selection", "clipboard", "-t", "text/html")
+	default:
+		return copyToClipboard(html)
+	}
+	if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+		return copyToClipboard(html)
+	}
+	cmd.Stdin = strings.NewReader(html)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", cmd.Args[0], strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// With AutoCopy, each completed response is copied to the clipboard in
+// CopyFormat, only failures are reported
+func autoCopy(config config.Config, response string) {
+	if !config.AutoCopy || response == "" {
+		return
+	}
+	if err := writeClipboard(response, config.CopyFormat); err != nil {
+		fmt.Printf("Error writing to clipboard: %v\n", err)
+	}
+}
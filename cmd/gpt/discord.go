@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/config"
+)
+
+const (
+	// Discord refuses longer messages
+	DISCORD_MAX_MESSAGE_LENGTH = 2000
+	// Responses are streamed by editing the message at most this often, to
+	// stay under the rate limits
+	DISCORD_EDIT_INTERVAL = time.Second
+)
+
+// Implements `go-gpt serve discord [--no-cache]`, DISCORD_BOT_TOKEN is read
+// from `.env`
+func runDiscordBridge(args []string) {
+	fs := flag.NewFlagSet("serve discord", flag.ExitOnError)
+	noCache := fs.Bool("no-cache", false, "don't use the response cache, even if Cache is set")
+	fs.Parse(args)
+
+	client, config, shutdown := setupServer(*noCache)
+	defer shutdown()
+	token := os.Getenv("DISCORD_BOT_TOKEN")
+	if token == "" {
+		fmt.Println("Error: DISCORD_BOT_TOKEN must be set in `.env`")
+		os.Exit(1)
+	}
+
+	discord, err := discordgo.New("Bot " + token)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	discord.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages | discordgo.IntentsMessageContent
+	discord.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
+		go answerDiscordMessage(client, config, s, m)
+	})
+	if err := discord.Open(); err != nil {
+		fmt.Printf("Error connecting to Discord: %v\n", err)
+		os.Exit(1)
+	}
+	defer discord.Close()
+	fmt.Println("Connected to Discord, press Ctrl-C to stop")
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	<-interrupt
+}
+
+// Answers direct messages and mentions. Every channel or thread is a
+// session, with the system prompt of `DiscordSystemPrompts` for the channel
+// if there is one.
+func answerDiscordMessage(client *openai.Client, config config.Config, s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.Author == nil || m.Author.Bot {
+		return
+	}
+	mentioned := false
+	for _, user := range m.Mentions {
+		if user.ID == s.State.User.ID {
+			mentioned = true
+		}
+	}
+	if !mentioned && m.GuildID != "" {
+		return
+	}
+	if !discordAllowed(config, m) {
+		s.ChannelMessageSendReply(m.ChannelID, fmt.Sprintf("You are not allowed to use this bot, add your user ID (%s) to `DiscordUsers` or this channel (%s) to `DiscordChannels` to allow it", m.Author.ID, m.ChannelID), m.Reference())
+		return
+	}
+	prompt := strings.NewReplacer("<@"+s.State.User.ID+">", "", "<@!"+s.State.User.ID+">", "").Replace(m.Content)
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return
+	}
+	if systemPrompt, ok := config.DiscordSystemPrompts[m.ChannelID]; ok {
+		config.SystemPrompt = systemPrompt
+	}
+
+	s.ChannelTyping(m.ChannelID)
+	stream := &discordStream{session: s, channelID: m.ChannelID, replyTo: m.Reference()}
+	serverMutex.Lock()
+	sess := botSession(config, "discord-"+m.ChannelID)
+	response, _, _, err := chatTurn(client, config, sess, prompt, stream.write, &toolCallPrinter{})
+	serverMutex.Unlock()
+	if err != nil {
+		response = fmt.Sprintf("Error: %v", err)
+	}
+	stream.finish(response)
+}
+
+// Anyone can talk to a bot, so only the users of `DiscordUsers` (user names
+// or IDs) and the channels of `DiscordChannels` are answered
+func discordAllowed(config config.Config, m *discordgo.MessageCreate) bool {
+	return slices.Contains(config.DiscordUsers, m.Author.Username) || slices.Contains(config.DiscordUsers, m.Author.ID) ||
+		m.GuildID != "" && slices.Contains(config.DiscordChannels, m.ChannelID)
+}
+
+// Streams a response by editing the message as it grows, continuing in a new
+// message when it gets too long
+type discordStream struct {
+	session   *discordgo.Session
+	channelID string
+	replyTo   *discordgo.MessageReference
+	// The message being edited, and what the previous messages contain
+	message  *discordgo.Message
+	sent     int
+	text     strings.Builder
+	lastEdit time.Time
+}
+
+func (d *discordStream) write(chunk string) {
+	d.text.WriteString(chunk)
+	if time.Since(d.lastEdit) >= DISCORD_EDIT_INTERVAL {
+		d.flush()
+	}
+}
+
+// Shows everything written so far
+func (d *discordStream) flush() {
+	d.lastEdit = time.Now()
+	pending := d.text.String()[d.sent:]
+	for pending != "" {
+		part := pending
+		if len(part) > DISCORD_MAX_MESSAGE_LENGTH {
+			cut := DISCORD_MAX_MESSAGE_LENGTH
+			for !utf8.RuneStart(part[cut]) {
+				cut--
+			}
+			part = part[:cut]
+		}
+		var err error
+		if d.message == nil {
+			d.message, err = d.session.ChannelMessageSendReply(d.channelID, part, d.replyTo)
+		} else {
+			d.message, err = d.session.ChannelMessageEdit(d.channelID, d.message.ID, part)
+		}
+		if err != nil {
+			fmt.Printf("Error answering in Discord: %v\n", err)
+			return
+		}
+		if len(part) == len(pending) {
+			return
+		}
+		// The message is full, the rest goes in a new one
+		d.sent += len(part)
+		d.message = nil
+		pending = pending[len(part):]
+	}
+}
+
+// Sends the final response, or an error when nothing was streamed
+func (d *discordStream) finish(response string) {
+	if d.text.Len() == 0 {
+		d.text.WriteString(response)
+	}
+	d.flush()
+}
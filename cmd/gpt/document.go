@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/render"
+)
+
+const (
+	FETCH_TIMEOUT           = 30 * time.Second
+	MAX_DOCUMENT_SIZE       = 10 * 1024 * 1024
+	DOCUMENT_SUMMARY_PROMPT = "Write a structured summary of the following document in Markdown, with these sections: " +
+		"`## TL;DR` (one or two sentences), `## Key points` (a bullet list) and `## Details` (anything else worth knowing, e.g. numbers, names, caveats). " +
+		"The document may be given as notes on its consecutive parts. Answer with the summary only."
+)
+
+var (
+	htmlIgnoredRe = regexp.MustCompile(`(?is)<(script|style|noscript|svg|head)\b.*?</(script|style|noscript|svg|head)>`)
+	htmlBlockRe   = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/h[1-6]|/tr|/section|/article)\b[^>]*>`)
+	htmlTagRe     = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinesRe  = regexp.MustCompile(`\n\s*\n\s*`)
+)
+
+// Reads a local file, or fetches the page of an http(s) URL as text
+func readDocument(source string) (string, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return fetchDocument(source)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", fmt.Errorf("can't read file `%s`", source)
+	}
+	if info.Size() > MAX_DOCUMENT_SIZE {
+		return "", fmt.Errorf("`%s` is bigger than %d bytes", source, MAX_DOCUMENT_SIZE)
+	}
+	if binary, err := isBinaryFile(source); err != nil || binary {
+		return "", fmt.Errorf("`%s` is a binary file", source)
+	}
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return "", fmt.Errorf("can't read file `%s`", source)
+	}
+	return string(content), nil
+}
+
+func fetchDocument(url string) (string, error) {
+	client := http.Client{Timeout: FETCH_TIMEOUT}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching `%s`: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MAX_DOCUMENT_SIZE))
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return htmlToText(string(body)), nil
+	}
+	return string(body), nil
+}
+
+// Good enough to feed a page to the model, not a real HTML parser
+func htmlToText(page string) string {
+	text := htmlIgnoredRe.ReplaceAllString(page, "")
+	text = htmlBlockRe.ReplaceAllString(text, "\n")
+	text = htmlTagRe.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(blankLinesRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// Map-reduce: long documents are summarized chunk by chunk, then the notes
+// are turned into the structured summary
+func summarizeDocument(client *openai.Client, model string, text string) (string, error) {
+	chunks := splitChunks(text, SUMMARY_CHUNK_SIZE)
+	if len(chunks) > 1 {
+		fmt.Printf("Summarizing %d chunks...\n", len(chunks))
+		notes := []string{}
+		for _, chunk := range chunks {
+			note, err := chat.Complete(client, model, CHUNK_SUMMARY_PROMPT, chunk)
+			if err != nil {
+				return "", err
+			}
+			notes = append(notes, note)
+		}
+		text = strings.Join(notes, "\n\n---\n\n")
+	}
+	return chat.Complete(client, model, DOCUMENT_SUMMARY_PROMPT, text)
+}
+
+// Handles `/summarize <url|file>`, the summary stays out of the history
+func summarizeCommand(client *openai.Client, config config.Config, source string) {
+	text, err := readDocument(source)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if strings.TrimSpace(text) == "" {
+		fmt.Printf("Error: `%s` has no text to summarize\n", source)
+		return
+	}
+	if !checkBudget(config) {
+		return
+	}
+	summary, err := summarizeDocument(client, config.Model, text)
+	if err != nil {
+		fmt.Printf("Error summarizing `%s`: %v\n", source, err)
+		return
+	}
+	render.Markdown(config, summary)
+	notifyWebhooks(config, EVENT_SUMMARIZE, map[string]any{"source": source, "summary": summary})
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/session"
+)
+
+const (
+	// Default for `MaxEmbedSize`
+	DEFAULT_MAX_EMBED_SIZE = 100 * 1024
+	// Number of bytes inspected to tell binary files apart
+	BINARY_SNIFF_SIZE = 8000
+)
+
+// Directories that are skipped when a directory is not in a git repository
+var skippedDirs = map[string]bool{
+	"vendor":       true,
+	"node_modules": true,
+	"target":       true,
+	"dist":         true,
+	"build":        true,
+	"__pycache__":  true,
+}
+
+func maxEmbedSize(config *config.Config) int64 {
+	if config.MaxEmbedSize <= 0 {
+		return DEFAULT_MAX_EMBED_SIZE
+	}
+	return config.MaxEmbedSize
+}
+
+func embedFile(fileName string) bool {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		fmt.Printf("Error: can't read file `%s`\n", fileName)
+		return false
+	}
+	addContextItem(session.ContextItem{Name: fileName, Kind: session.CONTEXT_FILE, Path: fileName, Content: string(content)})
+	return true
+}
+
+// Binary files are refused, and oversized ones can be embedded as a summary
+func embedSingleFile(client *openai.Client, config *config.Config, path string) {
+	skip, reason := shouldSkipFile(path, maxEmbedSize(config))
+	if !skip {
+		if embedFile(path) {
+			fmt.Printf("Added `%s` to system prompt\n", path)
+		}
+		return
+	}
+	switch reason {
+	case "binary":
+		fmt.Printf("Error: `%s` is a binary file\n", path)
+	case "oversized":
+		question := i18n.T("`%s` is bigger than %d bytes, embed a summary instead?", path, maxEmbedSize(config))
+		if !confirm(question) {
+			fmt.Printf("Skipped `%s`\n", path)
+			return
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("Error: can't read file `%s`\n", path)
+			return
+		}
+		fmt.Printf("Summarizing `%s`...\n", path)
+		summary, err := summarizeText(client, config.Model, string(content))
+		if err != nil {
+			fmt.Printf("Error summarizing `%s`: %v\n", path, err)
+			return
+		}
+		addContextItem(session.ContextItem{Name: path + " (summary)", Kind: session.CONTEXT_FILE, Path: path, Content: summary})
+		fmt.Printf("Added a summary of `%s` to system prompt\n", path)
+	default:
+		fmt.Printf("Error: can't read file `%s`\n", path)
+	}
+}
+
+func embedPath(client *openai.Client, config *config.Config, path string) {
+	switch path {
+	case "-":
+		fmt.Println("Paste the content to embed, then press Ctrl-D or enter a single `.`")
+		content, ok := readMultiline("... ")
+		if !ok || strings.TrimSpace(content) == "" {
+			fmt.Println("Nothing to embed")
+			return
+		}
+		addContextItem(session.ContextItem{Name: "stdin", Kind: session.CONTEXT_PASTE, Content: content})
+		fmt.Println("Added pasted content to system prompt")
+		return
+	case "clipboard":
+		content, err := clipboard.ReadAll()
+		if err != nil {
+			fmt.Printf("Error reading clipboard: %v\n", err)
+			return
+		}
+		if strings.TrimSpace(content) == "" {
+			fmt.Println("The clipboard is empty")
+			return
+		}
+		addContextItem(session.ContextItem{Name: "clipboard", Kind: session.CONTEXT_CLIPBOARD, Content: content})
+		fmt.Println("Added clipboard content to system prompt")
+		return
+	}
+
+	if !fileExists(path) && embedSelection(path) {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Printf("Error: can't read file `%s`\n", path)
+		return
+	}
+	if !info.IsDir() {
+		embedSingleFile(client, config, path)
+		return
+	}
+
+	files, err := gitFiles(path)
+	if err != nil {
+		files, err = walkFiles(path)
+	}
+	if err != nil {
+		fmt.Printf("Error: can't list directory `%s`: %v\n", path, err)
+		return
+	}
+
+	included := []string{}
+	skipped := 0
+	for _, file := range files {
+		if skip, _ := shouldSkipFile(file, maxEmbedSize(config)); skip {
+			skipped++
+			continue
+		}
+		if embedFile(file) {
+			included = append(included, file)
+		}
+	}
+
+	printFileTree(path, included)
+	fmt.Printf("Added %d files from `%s` to system prompt (%d binary or oversized files skipped)\n", len(included), path, skipped)
+}
+
+// Lists the tracked and untracked files that are not ignored by .gitignore
+func gitFiles(dir string) ([]string, error) {
+	cmd := exec.Command("git", "ls-files", "--cached", "--others", "--exclude-standard", "-z", "--", ".")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		// Deleted files are still listed until the deletion is staged
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Lists the files of a directory outside of a git repository, skipping the
+// ones ignored by the .gitignore files it contains
+func walkFiles(dir string) ([]string, error) {
+	files := []string{}
+	// The rules of the .gitignore files of every directory and its parents
+	rules := map[string][]ignoreRule{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		parentRules := rules[filepath.Dir(path)]
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(name, ".") || skippedDirs[name] || isIgnored(parentRules, path, true)) {
+				return filepath.SkipDir
+			}
+			rules[path] = append(slices.Clone(parentRules), readGitignore(path)...)
+			return nil
+		}
+		if d.Type().IsRegular() && !strings.HasPrefix(name, ".") && !isIgnored(parentRules, path, false) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func shouldSkipFile(path string, maxSize int64) (bool, string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return true, "unreadable"
+	}
+	if info.Size() > maxSize {
+		return true, "oversized"
+	}
+	binary, err := isBinaryFile(path)
+	if err != nil {
+		return true, "unreadable"
+	}
+	if binary {
+		return true, "binary"
+	}
+	return false, ""
+}
+
+// Like git, a file is considered binary if its beginning contains a NUL byte
+func isBinaryFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, BINARY_SNIFF_SIZE)
+	n, err := f.Read(buf)
+	if err != nil && n == 0 {
+		return false, nil
+	}
+	return bytes.IndexByte(buf[:n], 0) != -1, nil
+}
+
+func printFileTree(root string, files []string) {
+	fmt.Printf("%s/\n", filepath.Clean(root))
+	printed := map[string]bool{}
+	for _, file := range files {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			continue
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		for depth := range parts {
+			key := strings.Join(parts[:depth+1], "/")
+			if printed[key] {
+				continue
+			}
+			printed[key] = true
+			name := parts[depth]
+			if depth < len(parts)-1 {
+				name += "/"
+			}
+			fmt.Printf("%s%s\n", strings.Repeat("  ", depth+1), name)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+)
+
+const DEFAULT_EMBEDDING_MODEL = "text-embedding-3-small"
+
+func embeddingModel(config config.Config) string {
+	if config.EmbeddingModel == "" {
+		return DEFAULT_EMBEDDING_MODEL
+	}
+	return config.EmbeddingModel
+}
+
+func embedTexts(client *openai.Client, model string, texts []string) ([][]float32, error) {
+	start := time.Now()
+	ctx, cancel := chat.Context()
+	defer cancel()
+	resp, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input: texts,
+		Model: openai.EmbeddingModel(model),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Data))
+	}
+	recordUsage(chat.ResponseStats{
+		Model:        model,
+		Duration:     time.Since(start),
+		PromptTokens: resp.Usage.PromptTokens,
+	})
+
+	embeddings := make([][]float32, len(texts))
+	for _, data := range resp.Data {
+		embeddings[data.Index] = data.Embedding
+	}
+	return embeddings, nil
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
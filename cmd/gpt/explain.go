@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/render"
+)
+
+const EXPLAIN_PROMPT = "You explain source code to a developer who is new to it. Answer in Markdown with these sections: " +
+	"`## Purpose` (what the code is for), `## Key functions` (a bullet per important function, type or block and what it does), " +
+	"`## How it works` (the main flow) and `## Gotchas` (surprising behaviour, edge cases, possible bugs). Be concise."
+
+// Handles `/explain <file>`, selections such as `file:10-40` or
+// `file.go#Symbol` are supported. The explanation stays out of the history.
+func explainCommand(client *openai.Client, config config.Config, arg string) {
+	path, content, ok, err := readSelection(arg)
+	if !ok {
+		path = arg
+		if info, statErr := os.Stat(arg); statErr != nil {
+			err = fmt.Errorf("can't read file `%s`", arg)
+		} else if info.Size() > maxEmbedSize(&config) {
+			err = fmt.Errorf("`%s` is bigger than %d bytes, explain a selection such as `%s:1-200` instead", arg, maxEmbedSize(&config), arg)
+		} else {
+			content, err = readDocument(arg)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if !checkBudget(config) {
+		return
+	}
+	explanation, err := chat.Complete(client, config.Model, EXPLAIN_PROMPT, fmt.Sprintf("File `%s`:\n\n%s", path, content))
+	if err != nil {
+		fmt.Printf("Error explaining `%s`: %v\n", arg, err)
+		return
+	}
+	render.Markdown(config, explanation)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/render"
+	"gpt/tools"
+)
+
+const FILE_TOOL_CACHE_TTL = time.Minute
+
+var (
+	readFileParameters = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"path": {"type": "string", "description": "Path relative to the workspace root"}
+	},
+	"required": ["path"]
+}`)
+	writeFileParameters = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"path": {"type": "string", "description": "Path relative to the workspace root"},
+		"content": {"type": "string", "description": "The whole new content of the file"}
+	},
+	"required": ["path", "content"]
+}`)
+	listDirParameters = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"path": {"type": "string", "description": "Path relative to the workspace root, \".\" for the root"}
+	},
+	"required": ["path"]
+}`)
+)
+
+type pathArgs struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Adds the read_file, write_file and list_dir tools when a Workspace is set.
+// They can't reach outside of it.
+func enableFileTools(config config.Config) {
+	if config.Workspace == "" {
+		return
+	}
+	root, err := filepath.Abs(config.Workspace)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		fmt.Printf("Error: can't use workspace `%s`: %v\n", config.Workspace, err)
+		return
+	}
+	maxSize := maxEmbedSize(&config)
+
+	tools.Register("read_file", tools.Tool{
+		Description: "Reads a text file of the workspace",
+		Parameters:  readFileParameters,
+		Run: func(args string) (string, error) {
+			return readWorkspaceFile(root, maxSize, args)
+		},
+		CacheTTL: FILE_TOOL_CACHE_TTL,
+	})
+	tools.Register("write_file", tools.Tool{
+		Description: "Creates or overwrites a file of the workspace, the user has to confirm",
+		Parameters:  writeFileParameters,
+		Run: func(args string) (string, error) {
+			return writeWorkspaceFile(config, root, maxSize, args)
+		},
+	})
+	tools.Register("list_dir", tools.Tool{
+		Description: "Lists a directory of the workspace",
+		Parameters:  listDirParameters,
+		Run: func(args string) (string, error) {
+			return listWorkspaceDir(root, args)
+		},
+		CacheTTL: FILE_TOOL_CACHE_TTL,
+	})
+}
+
+// Resolves a path relative to the workspace root, refusing paths that end
+// up outside of it, symbolic links included
+func workspacePath(root string, args string) (string, pathArgs, error) {
+	var params pathArgs
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return "", params, fmt.Errorf("invalid arguments: %v", err)
+	}
+	resolved, ok := resolveInside(root, filepath.Join(root, filepath.FromSlash(params.Path)))
+	if !ok {
+		return "", params, fmt.Errorf("`%s` is outside of the workspace", params.Path)
+	}
+	return resolved, params, nil
+}
+
+// Resolves the symbolic links of path, and returns whether it is in root,
+// whose links are already resolved. The file may not exist yet when writing,
+// the links of its closest parent are resolved.
+func resolveInside(root string, path string) (string, bool) {
+	resolved, rest := path, ""
+	for {
+		if r, err := filepath.EvalSymlinks(resolved); err == nil {
+			resolved = filepath.Join(r, rest)
+			break
+		}
+		parent := filepath.Dir(resolved)
+		if parent == resolved {
+			break
+		}
+		rest = filepath.Join(filepath.Base(resolved), rest)
+		resolved = parent
+	}
+	rel, err := filepath.Rel(root, resolved)
+	return resolved, err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func readWorkspaceFile(root string, maxSize int64, args string) (string, error) {
+	path, params, err := workspacePath(root, args)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("can't read `%s`", params.Path)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("`%s` is a directory", params.Path)
+	}
+	if info.Size() > maxSize {
+		return "", fmt.Errorf("`%s` is bigger than the limit of %d bytes", params.Path, maxSize)
+	}
+	if binary, err := isBinaryFile(path); err != nil || binary {
+		return "", fmt.Errorf("`%s` is a binary file", params.Path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("can't read `%s`", params.Path)
+	}
+	return string(content), nil
+}
+
+func writeWorkspaceFile(config config.Config, root string, maxSize int64, args string) (string, error) {
+	path, params, err := workspacePath(root, args)
+	if err != nil {
+		return "", err
+	}
+	if int64(len(params.Content)) > maxSize {
+		return "", fmt.Errorf("the content is bigger than the limit of %d bytes", maxSize)
+	}
+
+	// Servers and bots have nobody to ask
+	if console == nil {
+		return "", fmt.Errorf("writing files needs a confirmation, which can only be given in the REPL")
+	}
+	question := "Create `%s` (%d bytes)?"
+	if _, err := os.Stat(path); err == nil {
+		question = "Overwrite `%s` (%d bytes)?"
+	}
+	fmt.Println()
+	old, _ := os.ReadFile(path)
+	render.Diff(config, params.Path, string(old), params.Content)
+	if !confirm(i18n.T(question, params.Path, len(params.Content))) {
+		return "", fmt.Errorf("the user refused to write `%s`", params.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(params.Content), 0644); err != nil {
+		return "", err
+	}
+	tools.Invalidate("read_file", "list_dir")
+	return fmt.Sprintf("Wrote %d bytes to `%s`", len(params.Content), params.Path), nil
+}
+
+func listWorkspaceDir(root string, args string) (string, error) {
+	path, params, err := workspacePath(root, args)
+	if err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", fmt.Errorf("can't list `%s`", params.Path)
+	}
+	sb := strings.Builder{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			sb.WriteString(entry.Name() + "/\n")
+			continue
+		}
+		size := int64(0)
+		if info, err := entry.Info(); err == nil {
+			size = info.Size()
+		}
+		sb.WriteString(fmt.Sprintf("%s (%d bytes)\n", entry.Name(), size))
+	}
+	if sb.Len() == 0 {
+		return "The directory is empty", nil
+	}
+	return sb.String(), nil
+}
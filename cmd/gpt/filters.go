@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"gpt/config"
+)
+
+// Values of config.ContentFilter.Action
+const (
+	FILTER_BLOCK  = "block"
+	FILTER_WARN   = "warn"
+	FILTER_REDACT = "redact"
+)
+
+const REDACTED = "[REDACTED]"
+
+type compiledFilter struct {
+	config.ContentFilter
+	re *regexp.Regexp
+}
+
+var filters []compiledFilter
+
+func loadFilters(configured []config.ContentFilter) error {
+	filters = nil
+	for _, filter := range configured {
+		re, err := regexp.Compile(filter.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern `%s`: %v", filter.Pattern, err)
+		}
+		switch filter.Action {
+		case FILTER_BLOCK, FILTER_WARN, FILTER_REDACT:
+		case "":
+			filter.Action = FILTER_BLOCK
+		default:
+			return fmt.Errorf("invalid action `%s` for pattern `%s`", filter.Action, filter.Pattern)
+		}
+		filters = append(filters, compiledFilter{filter, re})
+	}
+	return nil
+}
+
+// Returns the prompt with redactions applied, ok is false if it is blocked
+func filterPrompt(prompt string) (string, bool) {
+	for _, filter := range filters {
+		if !filter.re.MatchString(prompt) {
+			continue
+		}
+		switch filter.Action {
+		case FILTER_BLOCK:
+			fmt.Printf("Prompt blocked: it matches the filter `%s`\n", filter.Pattern)
+			return prompt, false
+		case FILTER_WARN:
+			fmt.Printf("Warning: prompt matches the filter `%s`\n", filter.Pattern)
+		case FILTER_REDACT:
+			prompt = filter.re.ReplaceAllString(prompt, REDACTED)
+			fmt.Printf("Redacted matches of `%s` from the prompt\n", filter.Pattern)
+		}
+	}
+	return prompt, true
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/render"
+)
+
+const (
+	DEFAULT_FIX_COMMAND = "go build ./... && go test ./..."
+	MAX_FIX_ATTEMPTS    = 5
+	// Files mentioned in the output that are sent along with it
+	MAX_FIX_FILES = 10
+)
+
+const FIX_PROMPT = "You fix build and test failures. Given the command, its output and the files it mentions, find the cause and fix it. " +
+	"Explain the fix in one or two sentences, then give the complete new content of every file you change, each introduced by a `FILE: <path>` line " +
+	"followed by a single code block. Only change what is needed."
+
+var (
+	fileReferenceRe = regexp.MustCompile(`([\w./\\-]+\.[A-Za-z0-9]+):\d+`)
+	filePatchRe     = regexp.MustCompile("(?s)FILE: `?([^\\s`]+)`?\\s*\n```[^\n]*\n(.*?)```")
+)
+
+// Runs a command line with the shell of the OS
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/c", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// The existing files referenced as `path:line` in the output
+func referencedFiles(output string, maxSize int64) []string {
+	seen := map[string]bool{}
+	files := []string{}
+	for _, match := range fileReferenceRe.FindAllStringSubmatch(output, -1) {
+		path := strings.TrimPrefix(match[1], "./")
+		if seen[path] || len(files) == MAX_FIX_FILES {
+			continue
+		}
+		seen[path] = true
+		if skip, _ := shouldSkipFile(path, maxSize); !skip {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+type filePatch struct {
+	Path    string
+	Content string
+}
+
+// Resolves the path of a patch. The paths are chosen by the model, patches
+// can only change the files of the working directory.
+func fixPatchPath(name string) (string, error) {
+	wd, err := os.Getwd()
+	if err == nil {
+		wd, err = filepath.EvalSymlinks(wd)
+	}
+	if err != nil {
+		return "", err
+	}
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(wd, path)
+	}
+	resolved, ok := resolveInside(wd, path)
+	if !ok {
+		return "", fmt.Errorf("`%s` is outside of the working directory", name)
+	}
+	return resolved, nil
+}
+
+func parseFilePatches(answer string) []filePatch {
+	patches := []filePatch{}
+	for _, match := range filePatchRe.FindAllStringSubmatch(answer, -1) {
+		patches = append(patches, filePatch{Path: match[1], Content: match[2]})
+	}
+	return patches
+}
+
+func fixInput(command string, output string, maxSize int64) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Command: `%s`\n\nOutput:\n```\n%s\n```\n", command, truncateDiff(output)))
+	for _, path := range referencedFiles(output, maxSize) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\nFILE: %s\n```\n%s```\n", path, content))
+	}
+	return sb.String()
+}
+
+// Handles `/fix [command]`: runs the command, asks for a fix of the failure,
+// applies it once confirmed, and starts again until the command succeeds
+func fixCommand(client *openai.Client, config config.Config, command string) {
+	if command == "" {
+		command = config.FixCommand
+	}
+	if command == "" {
+		command = DEFAULT_FIX_COMMAND
+	}
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: FIX_PROMPT},
+	}
+	for attempt := 0; ; attempt++ {
+		fmt.Printf("Running `%s`...\n", command)
+		output, err := shellCommand(command).CombinedOutput()
+		fmt.Print(string(output))
+		if err == nil {
+			fmt.Println("The command succeeded")
+			return
+		}
+		if attempt == MAX_FIX_ATTEMPTS {
+			fmt.Printf("Still failing after %d fixes, giving up\n", MAX_FIX_ATTEMPTS)
+			return
+		}
+		if !checkBudget(config) {
+			return
+		}
+
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleUser,
+			Content: fixInput(command, string(output), maxEmbedSize(&config)),
+		})
+		answer, err := chat.CompleteMessages(client, config.Model, messages)
+		if err != nil {
+			fmt.Printf("Error asking for a fix: %v\n", err)
+			return
+		}
+		messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: answer})
+
+		patches := parseFilePatches(answer)
+		if len(patches) == 0 {
+			render.Markdown(config, answer)
+			fmt.Println("No file changes were proposed")
+			return
+		}
+		explanation, _, _ := strings.Cut(answer, "FILE:")
+		render.Markdown(config, explanation)
+		paths := make([]string, len(patches))
+		for i, patch := range patches {
+			if paths[i], err = fixPatchPath(patch.Path); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+		}
+		for i, patch := range patches {
+			old, _ := os.ReadFile(paths[i])
+			render.Diff(config, patch.Path, string(old), patch.Content)
+		}
+		if !confirm(i18n.T("Apply the changes?")) {
+			return
+		}
+		for i, patch := range patches {
+			if err := os.WriteFile(paths[i], []byte(patch.Content), 0644); err != nil {
+				fmt.Printf("Error writing `%s`: %v\n", patch.Path, err)
+				return
+			}
+		}
+	}
+}
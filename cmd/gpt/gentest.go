@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/render"
+)
+
+// Rounds of feeding `go test` failures back to the model
+const MAX_GENTEST_FIXES = 3
+
+const GENTEST_PROMPT = "You write Go tests. Write table-driven tests using only the standard `testing` package for the exported functions and methods " +
+	"listed by the user, in the same package as the file. Cover normal cases, edge cases and errors. " +
+	"Answer with the complete `_test.go` file in a single ```go code block."
+
+const GENTEST_FIX_PROMPT = "`go test` failed with the output below. Fix the tests, unless the failure reveals a bug in the code under test, " +
+	"in which case keep the test and say so in a comment. Answer with the complete corrected file in a single ```go code block.\n\n%s"
+
+var codeBlockRe = regexp.MustCompile("(?s)```[a-zA-Z0-9_+-]*\n(.*?)```")
+
+// Returns the content of the first fenced code block, or the whole text
+func extractCodeBlock(text string) string {
+	if match := codeBlockRe.FindStringSubmatch(text); match != nil {
+		return match[1]
+	}
+	return text
+}
+
+func exportedFunctions(path string, src []byte) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.IsExported() {
+			names = append(names, goFuncName(fn))
+		}
+	}
+	return names, nil
+}
+
+// Handles `/gentest <file.go>`: writes generated tests next to the file, then
+// optionally runs them and asks for fixes until they pass
+func gentestCommand(client *openai.Client, config config.Config, path string) {
+	if filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") {
+		fmt.Println("Error: expected a Go source file")
+		return
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error: can't read file `%s`\n", path)
+		return
+	}
+	functions, err := exportedFunctions(path, src)
+	if err != nil {
+		fmt.Printf("Error: can't parse `%s`: %v\n", path, err)
+		return
+	}
+	if len(functions) == 0 {
+		fmt.Printf("`%s` has no exported functions\n", path)
+		return
+	}
+	if !checkBudget(config) {
+		return
+	}
+
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: GENTEST_PROMPT},
+		{Role: openai.ChatMessageRoleUser, Content: fmt.Sprintf("Functions: %s\n\nFile `%s`:\n\n%s", strings.Join(functions, ", "), path, src)},
+	}
+	testPath := strings.TrimSuffix(path, ".go") + "_test.go"
+	for fixes := 0; ; fixes++ {
+		fmt.Printf("Generating tests for %s...\n", strings.Join(functions, ", "))
+		answer, err := chat.CompleteMessages(client, config.Model, messages)
+		if err != nil {
+			fmt.Printf("Error generating tests: %v\n", err)
+			return
+		}
+		tests := extractCodeBlock(answer)
+
+		question := "Write `%s`?"
+		if old, err := os.ReadFile(testPath); err == nil {
+			question = "Overwrite `%s`?"
+			render.Diff(config, testPath, string(old), tests)
+		} else {
+			render.Markdown(config, "```go\n"+tests+"```")
+		}
+		if !confirm(i18n.T(question, testPath)) {
+			return
+		}
+		if err := os.WriteFile(testPath, []byte(tests), 0644); err != nil {
+			fmt.Printf("Error writing `%s`: %v\n", testPath, err)
+			return
+		}
+		if fixes == MAX_GENTEST_FIXES || !confirm(i18n.T("Run `go test`?")) {
+			return
+		}
+
+		cmd := exec.Command("go", "test", ".")
+		cmd.Dir = filepath.Dir(path)
+		output, err := cmd.CombinedOutput()
+		fmt.Print(string(output))
+		if err == nil {
+			fmt.Println("Tests pass")
+			return
+		}
+		if !confirm(i18n.T("Ask for a fix?")) || !checkBudget(config) {
+			return
+		}
+		messages = append(messages,
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: answer},
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: fmt.Sprintf(GENTEST_FIX_PROMPT, truncateDiff(string(output)))},
+		)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/config"
+)
+
+const HEALTH_TIMEOUT = 10 * time.Second
+
+// Checks that the API can be reached with the key, and that the configured
+// models exist. Prints what to fix and returns false otherwise.
+func checkHealth(client *openai.Client, config config.Config) bool {
+	if os.Getenv("OPENAI_API_KEY") == "" {
+		fmt.Println("Error: OPENAI_API_KEY is not set, add it to `.env`")
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), HEALTH_TIMEOUT)
+	defer cancel()
+	start := time.Now()
+	models, err := client.ListModels(ctx)
+	latency := time.Since(start)
+	if err != nil {
+		var apiErr *openai.APIError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.HTTPStatusCode == http.StatusUnauthorized:
+			fmt.Println("Error: the API key was rejected, check OPENAI_API_KEY in `.env` or create a new key at https://platform.openai.com/api-keys")
+		case isOffline(err):
+			fmt.Printf("Error: the API can't be reached, check your connection or proxy: %v\n", err)
+		default:
+			fmt.Printf("Error: %v\n", err)
+		}
+		return false
+	}
+	fmt.Printf("API reachable, key valid (%s round trip)\n", latency.Round(time.Millisecond))
+
+	available := map[string]bool{}
+	ids := []string{}
+	for _, model := range models.Models {
+		available[model.ID] = true
+		ids = append(ids, model.ID)
+	}
+	healthy := true
+	checked := map[string]bool{}
+	for _, model := range append([]string{config.Model}, config.CompareModels...) {
+		if model == "" || checked[model] {
+			continue
+		}
+		checked[model] = true
+		if available[model] {
+			fmt.Printf("Model `%s` available\n", model)
+			continue
+		}
+		healthy = false
+		fmt.Printf("Error: model `%s` doesn't exist or your key can't use it", model)
+		if similar := similarModels(model, ids); len(similar) > 0 {
+			fmt.Printf(", did you mean %s?", strings.Join(similar, ", "))
+		}
+		fmt.Printf("\nSet `Model` or `CompareModels` in `%s`\n", configFile)
+	}
+	return healthy
+}
+
+// Returns up to 5 models sharing the longest prefix with the given one
+func similarModels(model string, ids []string) []string {
+	commonPrefix := func(id string) int {
+		n := 0
+		for n < len(id) && n < len(model) && id[n] == model[n] {
+			n++
+		}
+		return n
+	}
+	similar := []string{}
+	for _, id := range ids {
+		if commonPrefix(id) >= 3 {
+			similar = append(similar, id)
+		}
+	}
+	sort.SliceStable(similar, func(i, j int) bool {
+		return commonPrefix(similar[i]) > commonPrefix(similar[j])
+	})
+	for i := range similar {
+		similar[i] = "`" + similar[i] + "`"
+	}
+	return similar[:min(len(similar), 5)]
+}
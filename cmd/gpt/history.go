@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	DEFAULT_HISTORY_COUNT = 10
+	PREVIEW_LENGTH        = 100
+)
+
+var roleStyles = map[string]lipgloss.Style{
+	openai.ChatMessageRoleSystem:    lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+	openai.ChatMessageRoleUser:      lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true),
+	openai.ChatMessageRoleAssistant: lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Bold(true),
+}
+
+// Rough estimate (~4 characters per token for English text), good enough to
+// know what is taking room in the context window
+func preview(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > length {
+		return truncate(text, length) + "..."
+	}
+	return text
+}
+
+// Cuts text to at most length bytes, without splitting a character
+func truncate(text string, length int) string {
+	if len(text) <= length {
+		return text
+	}
+	for length > 0 && !utf8.RuneStart(text[length]) {
+		length--
+	}
+	return text[:length]
+}
+
+// Messages loaded from old histories have no timestamp
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return strings.Repeat(" ", 5)
+	}
+	now := time.Now()
+	if t.Year() == now.Year() && t.YearDay() == now.YearDay() {
+		return t.Format("15:04")
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+func printHistory(n int) {
+	messages := currentSession.Messages
+	if len(messages) == 0 {
+		fmt.Println("The conversation is empty")
+		return
+	}
+	start := max(len(messages)-n, 0)
+	for i := start; i < len(messages); i++ {
+		msg := messages[i]
+		role := roleStyles[msg.Role].Render(fmt.Sprintf("%-9s", msg.Role))
+		tokens, exact := msg.Tokens()
+		count := fmt.Sprintf("%d tokens", tokens)
+		if !exact {
+			count = "~" + count
+		}
+		fmt.Printf("%4d %s %s %-12s %s\n", i+1, formatTimestamp(msg.Time), role, count, preview(msg.Content, PREVIEW_LENGTH))
+	}
+}
+
+var matchStyle = lipgloss.NewStyle().Reverse(true)
+
+func grepHistory(pattern string) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		fmt.Printf("Error: invalid regex: %v\n", err)
+		return
+	}
+	matches := 0
+	for i, msg := range currentSession.Messages {
+		for _, line := range strings.Split(msg.Content, "\n") {
+			if !re.MatchString(line) {
+				continue
+			}
+			highlighted := re.ReplaceAllStringFunc(line, func(match string) string {
+				return matchStyle.Render(match)
+			})
+			role := roleStyles[msg.Role].Render(fmt.Sprintf("%-9s", msg.Role))
+			fmt.Printf("%4d %s %s\n", i+1, role, highlighted)
+			matches++
+		}
+	}
+	if matches == 0 {
+		fmt.Println("No match")
+	}
+}
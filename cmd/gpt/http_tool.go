@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"gpt/config"
+	"gpt/tools"
+)
+
+const (
+	HTTP_TOOL_TIMEOUT      = 30 * time.Second
+	MAX_HTTP_RESPONSE_SIZE = 20000
+)
+
+var httpRequestParameters = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"method": {"type": "string", "enum": ["GET", "POST"]},
+		"url": {"type": "string"},
+		"headers": {"type": "object", "additionalProperties": {"type": "string"}},
+		"body": {"type": "string"}
+	},
+	"required": ["method", "url"]
+}`)
+
+// Adds the http_request tool when HTTPAllowList is set
+func enableHTTPTool(config config.Config) {
+	if len(config.HTTPAllowList) == 0 {
+		return
+	}
+	allowList := config.HTTPAllowList
+	client := &http.Client{
+		Timeout: HTTP_TOOL_TIMEOUT,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !hostAllowed(allowList, req.URL.Hostname()) {
+				return fmt.Errorf("redirect to `%s`, which is not in the allow-list", req.URL.Hostname())
+			}
+			return nil
+		},
+	}
+	tools.Register("http_request", tools.Tool{
+		Description: "Sends a GET or POST request, only to the domains allowed by the user: " + strings.Join(allowList, ", "),
+		Parameters:  httpRequestParameters,
+		Run: func(args string) (string, error) {
+			return httpRequest(client, allowList, args)
+		},
+	})
+}
+
+// `example.com` allows the domain and its subdomains, `*` allows everything
+func hostAllowed(allowList []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range allowList {
+		allowed = strings.ToLower(strings.TrimPrefix(allowed, "*."))
+		if allowed == "*" || host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func httpRequest(client *http.Client, allowList []string, args string) (string, error) {
+	var params struct {
+		Method  string            `json:"method"`
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+		Body    string            `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return "", fmt.Errorf("invalid arguments: %v", err)
+	}
+	method := strings.ToUpper(params.Method)
+	if method != http.MethodGet && method != http.MethodPost {
+		return "", fmt.Errorf("unsupported method `%s`, expected GET or POST", params.Method)
+	}
+	u, err := url.Parse(params.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("invalid URL `%s`", params.URL)
+	}
+	if !hostAllowed(allowList, u.Hostname()) {
+		return "", fmt.Errorf("`%s` is not in the allow-list", u.Hostname())
+	}
+
+	req, err := http.NewRequest(method, u.String(), strings.NewReader(params.Body))
+	if err != nil {
+		return "", err
+	}
+	for name, value := range params.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MAX_HTTP_RESPONSE_SIZE+1))
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(resp.Status + "\n")
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", name, resp.Header.Get(name)))
+	}
+	sb.WriteString("\n")
+	if len(body) > MAX_HTTP_RESPONSE_SIZE {
+		sb.Write(body[:MAX_HTTP_RESPONSE_SIZE])
+		sb.WriteString("\n[response truncated]")
+	} else {
+		sb.Write(body)
+	}
+	return sb.String(), nil
+}
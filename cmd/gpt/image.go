@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/session"
+)
+
+const (
+	DEFAULT_IMAGE_MODEL   = openai.CreateImageModelDallE3
+	DEFAULT_IMAGE_SIZE    = openai.CreateImageSize1024x1024
+	DEFAULT_IMAGE_QUALITY = openai.CreateImageQualityStandard
+	DEFAULT_IMAGES_DIR    = "images"
+)
+
+func imageSettings(config config.Config) (model, size, quality, dir string) {
+	model, size, quality, dir = config.ImageModel, config.ImageSize, config.ImageQuality, config.ImagesDir
+	if model == "" {
+		model = DEFAULT_IMAGE_MODEL
+	}
+	if size == "" {
+		size = DEFAULT_IMAGE_SIZE
+	}
+	if quality == "" {
+		quality = DEFAULT_IMAGE_QUALITY
+	}
+	if dir == "" {
+		dir = DEFAULT_IMAGES_DIR
+	}
+	return
+}
+
+// gpt-image models always answer with base64 data and reject the parameter
+func imageResponseFormat(model string) string {
+	if model == openai.CreateImageModelDallE2 || model == openai.CreateImageModelDallE3 {
+		return openai.CreateImageResponseFormatB64JSON
+	}
+	return ""
+}
+
+// Returns the paths of the generated images
+func imagine(client *openai.Client, config config.Config, prompt string) []string {
+	model, size, quality, dir := imageSettings(config)
+	fmt.Printf("Generating image with %s...\n", model)
+	ctx, cancel := chat.Context()
+	defer cancel()
+	resp, err := client.CreateImage(ctx, openai.ImageRequest{
+		Prompt:         prompt,
+		Model:          model,
+		N:              1,
+		Size:           size,
+		Quality:        quality,
+		ResponseFormat: imageResponseFormat(model),
+	})
+	if err != nil {
+		fmt.Printf("Error generating image: %v\n", err)
+		return nil
+	}
+	return saveImages(resp, dir, slugify(prompt))
+}
+
+func saveImages(resp openai.ImageResponse, dir string, name string) []string {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Printf("Error creating `%s`: %v\n", dir, err)
+		return nil
+	}
+	if len(name) > 40 {
+		name = name[:40]
+	}
+	paths := []string{}
+	stamp := time.Now().Format(session.ID_FORMAT)
+	for i, image := range resp.Data {
+		data, err := imageData(image)
+		if err != nil {
+			fmt.Printf("Error downloading image: %v\n", err)
+			continue
+		}
+		filename := fmt.Sprintf("%s-%s.png", stamp, name)
+		if len(resp.Data) > 1 {
+			filename = fmt.Sprintf("%s-%s-%d.png", stamp, name, i+1)
+		}
+		path := filepath.Join(dir, filename)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			fmt.Printf("Error writing `%s`: %v\n", path, err)
+			continue
+		}
+		if image.RevisedPrompt != "" {
+			fmt.Printf("Revised prompt: %s\n", image.RevisedPrompt)
+		}
+		fmt.Printf("Image saved to `%s`\n", path)
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func imageData(image openai.ImageResponseDataInner) ([]byte, error) {
+	if image.B64JSON != "" {
+		return base64.StdEncoding.DecodeString(image.B64JSON)
+	}
+	resp, err := http.Get(image.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Only dall-e-2 and gpt-image models support edits and variations
+func imageEditModel(model string) string {
+	if model == openai.CreateImageModelDallE3 {
+		return openai.CreateImageModelDallE2
+	}
+	return model
+}
+
+func imageEditSize(size string) string {
+	if size == openai.CreateImageSize1792x1024 || size == openai.CreateImageSize1024x1792 {
+		return openai.CreateImageSize1024x1024
+	}
+	return size
+}
+
+// Edits the image following the instructions, or creates a variation of it
+// when there are none. Results are written next to the source image.
+func editImage(client *openai.Client, config config.Config, path string, maskPath string, instructions string) []string {
+	model, size, _, _ := imageSettings(config)
+	model = imageEditModel(model)
+	size = imageEditSize(size)
+
+	image, err := os.Open(path)
+	if err != nil {
+		fmt.Printf("Error opening `%s`: %v\n", path, err)
+		return nil
+	}
+	defer image.Close()
+
+	ctx, cancel := chat.Context()
+	defer cancel()
+	var resp openai.ImageResponse
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if instructions == "" {
+		if maskPath != "" {
+			fmt.Println("Error: a mask requires edit instructions")
+			return nil
+		}
+		fmt.Printf("Creating a variation with %s...\n", openai.CreateImageModelDallE2)
+		resp, err = client.CreateVariImage(ctx, openai.ImageVariRequest{
+			Image:          image,
+			Model:          openai.CreateImageModelDallE2,
+			N:              1,
+			Size:           size,
+			ResponseFormat: openai.CreateImageResponseFormatB64JSON,
+		})
+		name += "-variation"
+	} else {
+		req := openai.ImageEditRequest{
+			Image:          image,
+			Prompt:         instructions,
+			Model:          model,
+			N:              1,
+			Size:           size,
+			ResponseFormat: imageResponseFormat(model),
+		}
+		if maskPath != "" {
+			mask, err := os.Open(maskPath)
+			if err != nil {
+				fmt.Printf("Error opening `%s`: %v\n", maskPath, err)
+				return nil
+			}
+			defer mask.Close()
+			req.Mask = mask
+		}
+		fmt.Printf("Editing image with %s...\n", model)
+		resp, err = client.CreateEditImage(ctx, req)
+		name += "-edit"
+	}
+	if err != nil {
+		fmt.Printf("Error editing image: %v\n", err)
+		return nil
+	}
+	return saveImages(resp, filepath.Dir(path), name)
+}
+
+// Parses `<path> [--mask <mask>] ["<instructions>"]`
+func parseImageEditArgs(rest string) (path string, mask string, instructions string, ok bool) {
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", "", "", false
+	}
+	path = fields[0]
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, path))
+	if strings.HasPrefix(rest, "--mask") {
+		fields = strings.Fields(rest)
+		if len(fields) < 2 {
+			return "", "", "", false
+		}
+		mask = fields[1]
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, "--mask"))
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, mask))
+	}
+	instructions = strings.Trim(rest, "\"")
+	return path, mask, instructions, true
+}
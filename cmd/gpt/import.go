@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/session"
+)
+
+// Converters from the formats of other chat clients to sessions. The path
+// of the imported file is used to name conversations that have no title.
+var importers = map[string]func(path string, data []byte) ([]*session.Session, error){
+	"chatgpt": importChatGPT,
+	"sgpt":    importSgpt,
+	"aichat":  importAichat,
+	"ollama":  importOllama,
+	"bundle":  importBundle,
+}
+
+// Implements `go-gpt import <format> <file>`
+func runImportCommand(args []string) {
+	if len(args) != 2 {
+		fmt.Printf("Usage: %s import <%s> <file>\n", os.Args[0], strings.Join(importFormats(), " | "))
+		os.Exit(1)
+	}
+	format, path := args[0], args[1]
+	importer, ok := importers[format]
+	if !ok {
+		fmt.Printf("Error: unknown import format `%s`, expected one of: %s\n", format, strings.Join(importFormats(), ", "))
+		os.Exit(1)
+	}
+
+	// Imported sessions are encrypted like the others, and so are bundles
+	if err := session.SetupEncryption(loadConfig()); err != nil {
+		fmt.Printf("Error: can't set up encryption: %v\n", err)
+		os.Exit(1)
+	}
+	data, err := session.ReadSecureFile(path)
+	if err != nil {
+		fmt.Printf("Error reading `%s`: %v\n", path, err)
+		os.Exit(1)
+	}
+	sessions, err := importer(path, data)
+	if err != nil {
+		fmt.Printf("Error importing `%s`: %v\n", path, err)
+		os.Exit(1)
+	}
+
+	imported := 0
+	for _, sess := range sessions {
+		if len(sess.Messages) == 0 {
+			continue
+		}
+		if err := session.Write(sess); err != nil {
+			fmt.Printf("Error writing session `%s`: %v\n", sess.DisplayName(), err)
+			continue
+		}
+		imported++
+	}
+	fmt.Printf("Imported %d conversations into `%s`\n", imported, session.DIR)
+}
+
+func importFormats() []string {
+	formats := make([]string, 0, len(importers))
+	for format := range importers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Seconds since the epoch with a fractional part, as used by the ChatGPT export
+func unixFloat(seconds float64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+type chatGPTConversation struct {
+	ID          string                 `json:"id"`
+	Title       string                 `json:"title"`
+	CreateTime  float64                `json:"create_time"`
+	UpdateTime  float64                `json:"update_time"`
+	CurrentNode string                 `json:"current_node"`
+	Mapping     map[string]chatGPTNode `json:"mapping"`
+}
+
+type chatGPTNode struct {
+	Parent  string          `json:"parent"`
+	Message *chatGPTMessage `json:"message"`
+}
+
+type chatGPTMessage struct {
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	CreateTime float64 `json:"create_time"`
+	Content    struct {
+		ContentType string            `json:"content_type"`
+		Parts       []json.RawMessage `json:"parts"`
+	} `json:"content"`
+	Metadata struct {
+		ModelSlug string `json:"model_slug"`
+	} `json:"metadata"`
+}
+
+// The export (`conversations.json`) stores every conversation as a tree of
+// messages, the displayed branch being the path from `current_node` to the root
+func importChatGPT(path string, data []byte) ([]*session.Session, error) {
+	var conversations []chatGPTConversation
+	if err := json.Unmarshal(data, &conversations); err != nil {
+		return nil, err
+	}
+
+	sessions := []*session.Session{}
+	for _, conv := range conversations {
+		branch := []*chatGPTMessage{}
+		for id := conv.CurrentNode; id != ""; {
+			node, ok := conv.Mapping[id]
+			if !ok {
+				break
+			}
+			if node.Message != nil {
+				branch = append(branch, node.Message)
+			}
+			id = node.Parent
+		}
+
+		sess := &session.Session{
+			ID:      "chatgpt-" + conv.ID,
+			Title:   conv.Title,
+			Created: unixFloat(conv.CreateTime),
+			Updated: unixFloat(conv.UpdateTime),
+		}
+		for i := len(branch) - 1; i >= 0; i-- {
+			msg := branch[i]
+			role := msg.Author.Role
+			if role != openai.ChatMessageRoleUser && role != openai.ChatMessageRoleAssistant {
+				continue
+			}
+			content := chatGPTText(msg)
+			if content == "" {
+				continue
+			}
+			sess.Messages = append(sess.Messages, session.Message{
+				Role:    role,
+				Content: content,
+				Time:    unixFloat(msg.CreateTime),
+				Model:   msg.Metadata.ModelSlug,
+			})
+			if msg.Metadata.ModelSlug != "" {
+				sess.Model = msg.Metadata.ModelSlug
+			}
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, nil
+}
+
+// Only text parts are kept, images and attachments are dropped
+func chatGPTText(msg *chatGPTMessage) string {
+	if msg.Content.ContentType != "text" && msg.Content.ContentType != "multimodal_text" {
+		return ""
+	}
+	parts := []string{}
+	for _, raw := range msg.Content.Parts {
+		var text string
+		if json.Unmarshal(raw, &text) == nil && text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"gopkg.in/yaml.v3"
+
+	"gpt/session"
+)
+
+// Importers for the histories of other terminal chat clients. None of them
+// store timestamps, so the modification time of the file is used instead.
+
+func importedSession(format string, path string) *session.Session {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	updated := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		updated = info.ModTime()
+	}
+	return &session.Session{
+		ID:      format + "-" + slugify(name),
+		Title:   name,
+		Created: updated,
+		Updated: updated,
+	}
+}
+
+// System messages become the session system prompt
+func addImportedMessage(sess *session.Session, role string, content string) {
+	switch role {
+	case openai.ChatMessageRoleSystem:
+		if sess.SystemPrompt != "" {
+			sess.SystemPrompt += "\n"
+		}
+		sess.SystemPrompt += content
+	case openai.ChatMessageRoleUser:
+		sess.Messages = append(sess.Messages, session.Message{Role: role, Content: content})
+	case openai.ChatMessageRoleAssistant:
+		sess.Messages = append(sess.Messages, session.Message{Role: role, Content: content, Model: sess.Model})
+	}
+}
+
+// shell_gpt stores every chat in `~/.config/shell_gpt/chat_cache/<chat id>`
+// as a JSON array of API messages
+func importSgpt(path string, data []byte) ([]*session.Session, error) {
+	var messages []struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, err
+	}
+	sess := importedSession("sgpt", path)
+	for _, msg := range messages {
+		addImportedMessage(sess, msg.Role, msg.Content)
+	}
+	return []*session.Session{sess}, nil
+}
+
+// aichat stores sessions in `~/.config/aichat/sessions/<name>.yaml`
+func importAichat(path string, data []byte) ([]*session.Session, error) {
+	var file struct {
+		Model    string `yaml:"model"`
+		Messages []struct {
+			Role    string `yaml:"role"`
+			Content any    `yaml:"content"`
+		} `yaml:"messages"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	sess := importedSession("aichat", path)
+	// Models are prefixed with the client name, e.g. `openai:gpt-4o`
+	if _, model, ok := strings.Cut(file.Model, ":"); ok {
+		sess.Model = model
+	} else {
+		sess.Model = file.Model
+	}
+	for _, msg := range file.Messages {
+		addImportedMessage(sess, msg.Role, aichatText(msg.Content))
+	}
+	return []*session.Session{sess}, nil
+}
+
+// Content is either a string or a list of parts, of which only text is kept
+func aichatText(content any) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []any:
+		parts := []string{}
+		for _, part := range c {
+			if m, ok := part.(map[string]any); ok {
+				if text, ok := m["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
+
+// Ollama saves conversations (`/save <name>`) as models, whose Modelfile
+// (`ollama show --modelfile <name>`) replays them with MESSAGE instructions
+func importOllama(path string, data []byte) ([]*session.Session, error) {
+	sess := importedSession("ollama", path)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		instruction, rest, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(instruction) {
+		case "FROM":
+			sess.Model = strings.TrimSpace(rest)
+		case "SYSTEM":
+			addImportedMessage(sess, openai.ChatMessageRoleSystem, readModelfileValue(scanner, rest))
+		case "MESSAGE":
+			role, content, _ := strings.Cut(strings.TrimSpace(rest), " ")
+			addImportedMessage(sess, strings.ToLower(role), readModelfileValue(scanner, content))
+		}
+	}
+	return []*session.Session{sess}, scanner.Err()
+}
+
+// Values may span several lines when wrapped in triple quotes
+func readModelfileValue(scanner *bufio.Scanner, value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, `"""`) {
+		return strings.Trim(value, `"`)
+	}
+	value = strings.TrimPrefix(value, `"""`)
+	if strings.HasSuffix(value, `"""`) {
+		return strings.TrimSuffix(value, `"""`)
+	}
+	lines := []string{value}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasSuffix(line, `"""`) {
+			lines = append(lines, strings.TrimSuffix(line, `"""`))
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
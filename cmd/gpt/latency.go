@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// Latencies compared to detect a slowdown: the median of the last
+	// SLOW_RECENT requests against the median of the LATENCY_BASELINE before
+	LATENCY_BASELINE = 20
+	SLOW_RECENT      = 3
+	SLOW_MIN_SAMPLES = 5
+	// Latencies kept per model
+	LATENCY_WINDOW = LATENCY_BASELINE + SLOW_RECENT
+	// How much slower than the baseline counts as a slowdown
+	SLOW_FACTOR = 2.0
+	// Under this, slowdowns are not worth a warning
+	SLOW_MIN_LATENCY = time.Second
+	HISTOGRAM_WIDTH  = 30
+)
+
+var latencyBuckets = []time.Duration{
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	4 * time.Second,
+	8 * time.Second,
+}
+
+// The last LATENCY_WINDOW latencies of a model, in a ring buffer
+type latencyWindow struct {
+	values []time.Duration
+	next   int
+}
+
+func (w *latencyWindow) add(latency time.Duration) {
+	if len(w.values) < LATENCY_WINDOW {
+		w.values = append(w.values, latency)
+		return
+	}
+	w.values[w.next] = latency
+	w.next = (w.next + 1) % LATENCY_WINDOW
+}
+
+// The latencies, oldest first
+func (w latencyWindow) list() []time.Duration {
+	return append(append([]time.Duration{}, w.values[w.next:]...), w.values[:w.next]...)
+}
+
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(p*float64(len(sorted)-1)+0.5)]
+}
+
+// Warns once when a model becomes much slower than its baseline, and again
+// after it recovered
+func checkSlowdown(model string, usage *ModelUsage) {
+	latencies := usage.Latencies.list()
+	n := len(latencies)
+	if n < SLOW_RECENT+SLOW_MIN_SAMPLES {
+		return
+	}
+	recent := percentile(latencies[n-SLOW_RECENT:], 0.5)
+	baseline := percentile(latencies[:n-SLOW_RECENT], 0.5)
+	slow := recent >= SLOW_MIN_LATENCY && float64(recent) > SLOW_FACTOR*float64(baseline)
+	if slow && !usage.SlowWarned {
+		fmt.Printf("Warning: `%s` is slower than usual, %s to respond instead of %s\n", model, recent.Round(time.Millisecond), baseline.Round(time.Millisecond))
+	}
+	usage.SlowWarned = slow
+}
+
+func printLatencyHistogram(names []string) {
+	labels := []string{}
+	for i, bound := range latencyBuckets {
+		if i == 0 {
+			labels = append(labels, "< "+bound.String())
+		} else {
+			labels = append(labels, latencyBuckets[i-1].String()+"-"+bound.String())
+		}
+	}
+	labels = append(labels, ">= "+latencyBuckets[len(latencyBuckets)-1].String())
+
+	for _, name := range names {
+		latencies := sessionStats.Models[name].Latencies.list()
+		counts := make([]int, len(labels))
+		for _, latency := range latencies {
+			bucket := sort.Search(len(latencyBuckets), func(i int) bool { return latency < latencyBuckets[i] })
+			counts[bucket]++
+		}
+		most := 0
+		for _, count := range counts {
+			most = max(most, count)
+		}
+		fmt.Printf("%s: p50 %s, p90 %s, p99 %s\n", name,
+			percentile(latencies, 0.5).Round(time.Millisecond),
+			percentile(latencies, 0.9).Round(time.Millisecond),
+			percentile(latencies, 0.99).Round(time.Millisecond),
+		)
+		for i, label := range labels {
+			bar := strings.Repeat("#", (counts[i]*HISTOGRAM_WIDTH+most-1)/max(most, 1))
+			fmt.Printf("  %-12s %-*s %d\n", label, HISTOGRAM_WIDTH, bar, counts[i])
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/chzyer/readline"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/commands"
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/provider"
+	"gpt/render"
+	"gpt/session"
+	"gpt/tools"
+)
+
+const (
+	CONFIG_NAME  = "gpt_config"
+	LEDGER_FILE  = "gpt_usage.jsonl"
+	RATINGS_FILE = "gpt_ratings.jsonl"
+	MEMORY_FILE  = "gpt_memory.json"
+)
+
+var (
+	history session.History
+	// System prompt of the config, restored by `/system reset`
+	defaultSystemPrompt string
+	// Last response, copied by `/copy`
+	chatResponse strings.Builder
+	// gpt_config.toml, .yaml, .yml or .json
+	configFile = config.Find(CONFIG_NAME)
+	// Files the config was read from, in order
+	configFiles []string
+)
+
+func saveHistory(path string) {
+	// The history loses the messages folded into the summary, the session
+	// keeps all of them
+	data, err := session.EncodeHistory(currentSession.Messages)
+	if err != nil {
+		fmt.Printf("Error saving `%s`: %v\n", path, err)
+		return
+	}
+	if err := session.WriteSecureFile(path, data); err != nil {
+		fmt.Printf("Error writing `%s`: %v\n", path, err)
+		return
+	}
+	fmt.Printf("History saved to `%s`\n", path)
+}
+
+func loadHistory(path string) {
+	data, err := session.ReadSecureFile(path)
+	if err != nil {
+		fmt.Printf("Error reading `%s`: %v\n", path, err)
+		return
+	}
+	messages, err := session.DecodeHistory(data)
+	if err != nil {
+		fmt.Printf("Error loading `%s`: %v\n", path, err)
+		return
+	}
+	history.Reset(messages)
+	currentSession.Messages = append([]session.Message{}, messages...)
+	fmt.Printf("Loaded history from `%s`\n", path)
+}
+
+func buildCompleter(prefix string) *readline.PrefixCompleter {
+	pcCommands := []readline.PrefixCompleterInterface{}
+	for _, cmd := range commands.All() {
+		pcArgs := []readline.PrefixCompleterInterface{}
+		for _, arg := range cmd.Args {
+			pcArgs = append(pcArgs, readline.PcItem(arg))
+		}
+		pcCommands = append(pcCommands, readline.PcItem(prefix+cmd.Name, pcArgs...))
+	}
+	return readline.NewPrefixCompleter(pcCommands...)
+}
+
+// Loads the config file and the project config over it
+func loadConfig() config.Config {
+	wd, _ := os.Getwd()
+	c, files, err := config.LoadLayers(configFile, wd)
+	if err != nil {
+		log.Fatalf("Fatal error: can't load config file: %v", err)
+	}
+	configFiles = files
+	render.Accessible = c.Accessible
+	return c
+}
+
+func saveConfig(c config.Config) {
+	if err := config.Save(configFile, c); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+	}
+}
+
+// Printed in place of the secrets of the config
+const MASKED_SECRET = "********"
+
+// Fields of the config that are never shown
+var secretFields = []string{"EncryptionPassphrase", "ServerToken"}
+
+// The config with its secrets masked, to be shown
+func maskSecrets(c config.Config) config.Config {
+	v := reflect.ValueOf(&c).Elem()
+	for _, name := range secretFields {
+		if field := v.FieldByName(name); field.String() != "" {
+			field.SetString(MASKED_SECRET)
+		}
+	}
+	return c
+}
+
+func printConfig(config config.Config) {
+	data, err := toml.Marshal(maskSecrets(config))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(data))
+}
+
+// Names of the config fields, for suggestions
+func configFields() []string {
+	fields := []string{}
+	configType := reflect.TypeOf(config.Config{})
+	for i := range configType.NumField() {
+		fields = append(fields, configType.Field(i).Name)
+	}
+	return fields
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "usage":
+			runUsageCommand(os.Args[2:])
+			return
+		case "import":
+			runImportCommand(os.Args[2:])
+			return
+		case "review":
+			runReviewCommand(os.Args[2:])
+			return
+		case "serve":
+			runServeCommand(os.Args[2:])
+			return
+		case "schedule":
+			runScheduleCommand(os.Args[2:])
+			return
+		case "watch":
+			runWatchCommand(os.Args[2:])
+			return
+		case "voice":
+			voiceMode = true
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		}
+	}
+
+	continueLast := flag.Bool("continue", false, "resume the most recently active session")
+	noCache := flag.Bool("no-cache", false, "don't use the response cache, even if Cache is set")
+	accessible := flag.Bool("accessible", false, "screen reader friendly output, even if Accessible is not set")
+	flag.Parse()
+
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	config := loadConfig()
+	fileConfig = config
+	if err := i18n.SetLanguage(config.Language); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+	config.Accessible = config.Accessible || *accessible
+	client := provider.NewClient(config, os.Getenv("OPENAI_API_KEY"))
+	chat.CacheResponses = config.Cache && !*noCache
+	chat.Timeout = time.Duration(config.Timeout)
+	defer chat.SetupTracing(config)()
+	if err := session.SetupEncryption(config); err != nil {
+		log.Fatalf("Fatal error: can't set up encryption: %v", err)
+	}
+	if config.Transcript != "" {
+		if err := startTranscript(config.Transcript); err != nil {
+			fmt.Printf("Error: can't start the transcript: %v\n", err)
+		}
+	}
+	defer stopTranscript()
+	provider.LoadPricing(config.Pricing)
+	enableCodeExecution(config)
+	enableFileTools(config)
+	enableHTTPTool(config)
+	if err := loadFilters(config.Filters); err != nil {
+		log.Fatalf("Fatal error: can't load filters: %v", err)
+	}
+	loadMemories()
+	fmt.Print(i18n.T("GPT Client in Go. Use `%shelp` for help.\n", config.CommandPrefix))
+	if config.HealthCheck {
+		checkHealth(client, config)
+	}
+	if voiceMode {
+		voiceMode = false
+		toggleVoiceMode(config)
+	}
+	if config.WatchClipboard {
+		toggleClipboardWatch()
+	}
+
+	defaultSystemPrompt = config.SystemPrompt
+	if *continueLast {
+		continueLastSession(&config)
+	} else {
+		pickRecentSession(&config)
+	}
+	completer := buildCompleter(config.CommandPrefix)
+
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:          sessionPrompt(),
+		AutoComplete:    completer,
+		HistoryFile:     "/tmp/gpt_repl_history.tmp",
+		InterruptPrompt: "^C",
+		EOFPrompt:       "exit",
+		Listener:        readline.FuncListener(pasteListener),
+		Stdin:           newPasteReader(readline.NewCancelableStdin(readline.Stdin)),
+	})
+	if err != nil {
+		log.Fatalf("readline error: %v", err)
+	}
+	defer rl.Close()
+	if readline.DefaultIsTerminal() {
+		enableBracketedPaste(true)
+		defer enableBracketedPaste(false)
+	}
+	console = rl
+	for _, path := range config.Embed {
+		embedPath(client, &config, path)
+	}
+
+	for {
+		// Applies `/config Language`, `/config Accessible` and `/config Timeout`
+		// from the next message
+		i18n.SetLanguage(config.Language)
+		render.Accessible = config.Accessible
+		chat.Timeout = time.Duration(config.Timeout)
+		applyGeneratedTitles()
+		rl.SetPrompt(sessionPrompt())
+		var line string
+		if replay, ok := nextReplay(); ok && pendingInput == "" {
+			pendingInput = replay
+		}
+		if pendingInput != "" {
+			line, pendingInput = pendingInput, ""
+			fmt.Println(sessionPrompt() + " " + line)
+		} else {
+			input, err := rl.ReadlineWithDefault(draftPrompt)
+			draftPrompt = ""
+			if err != nil {
+				break
+			}
+			input = expandPastes(input)
+			if voiceMode && input == "" {
+				if input = recordUtterance(client); input == "" {
+					continue
+				}
+				fmt.Println(sessionPrompt() + " " + input)
+			} else {
+				logPrompt(sessionPrompt(), input)
+			}
+			line = input
+		}
+
+		// A dropped file is attached rather than sent, or taken for a command
+		if paths, onlyPaths := droppedPaths(line); onlyPaths {
+			attachPaths(client, &config, paths)
+			continue
+		}
+
+		if line[0] == []byte(config.CommandPrefix)[0] {
+			ctx := &commands.Context{Client: client, Config: &config}
+			commands.Run(ctx, line[1:])
+			if ctx.Exit {
+				break
+			}
+		} else if line[0] == '!' {
+			runCommandContext(strings.TrimSpace(line[1:]))
+		} else {
+			typed := line
+			overrides, line, err := parseOverrides(line)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			offerClipboard()
+			if paths, _ := droppedPaths(line); len(paths) > 0 {
+				attachPaths(client, &config, paths)
+			}
+			line, ok := filterPrompt(line)
+			if !ok || !checkBudget(config) || !moderate(client, config, MODERATE_PROMPTS, line) {
+				continue
+			}
+			recallMemories(client, config, line)
+			appendMessage(session.NewMessage(openai.ChatMessageRoleUser, line))
+
+			chatResponse.Reset()
+			req := chat.NewRequest(config, config.Model, buildMessages(config))
+			overrides.Apply(&req)
+			printer := &responsePrinter{}
+			fullRes, stats, err := tools.Stream(client, req, func(chunk string) {
+				chatResponse.WriteString(chunk)
+				printer.chunk(chunk)
+			}, &toolCallPrinter{})
+			printer.done()
+			if err != nil && isOffline(err) {
+				dropLastMessage()
+				offerQueue(typed, err)
+				continue
+			}
+			if err != nil {
+				fmt.Printf("ChatCompletionStream error: %v\n", err)
+				dropLastMessage()
+				continue
+			}
+			offerReplay()
+			if !moderate(client, config, MODERATE_RESPONSES, fullRes) {
+				fmt.Println("The prompt and response were removed from the history")
+				dropLastMessage()
+				recordUsage(stats)
+				continue
+			}
+			appendMessage(newResponseMessage(fullRes, stats))
+			autoCopy(config, fullRes)
+			// The rendered copy would be read twice by screen readers
+			if config.RenderMarkdown && !config.Accessible {
+				out, _ := glamour.Render(fullRes, config.Theme)
+				fmt.Println("\n--- Rendered Markdown ---")
+				fmt.Print(out)
+			}
+			fmt.Println()
+			recordUsage(stats)
+			sessionStats.CountMessage(openai.ChatMessageRoleUser)
+			sessionStats.CountMessage(openai.ChatMessageRoleAssistant)
+			lastExchange = &Exchange{
+				Prompt:       line,
+				Response:     fullRes,
+				Model:        req.Model,
+				SystemPrompt: config.SystemPrompt,
+			}
+			maybeGenerateTitle(client, config, line, fullRes)
+			compactHistory(client, config)
+			archiveSession(config)
+			if config.ShowStats {
+				printResponseStats(stats)
+			}
+			if voiceMode {
+				speak(client, config, fullRes)
+			}
+		}
+	}
+
+	archiveSession(config)
+	rememberSession()
+}
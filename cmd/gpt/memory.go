@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/config"
+	"gpt/session"
+)
+
+const (
+	MEMORY_FACT    = "fact"
+	MEMORY_SUMMARY = "summary"
+)
+
+type Memory struct {
+	Kind      string    `json:"kind,omitempty"`
+	Text      string    `json:"text"`
+	Created   time.Time `json:"created"`
+	Embedding []float32 `json:"embedding,omitempty"`
+	// Model the embedding was computed with
+	EmbeddingModel string `json:"embedding_model,omitempty"`
+}
+
+func (m Memory) IsSummary() bool {
+	return m.Kind == MEMORY_SUMMARY
+}
+
+var (
+	// Facts stored with `/remember` and summaries of past sessions, persisted across sessions
+	memories []Memory
+	// Memories relevant to the current question, when `MemoryRecall` is set
+	recalledMemories []Memory
+)
+
+func loadMemories() {
+	data, err := session.ReadSecureFile(MEMORY_FILE)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error reading `%s`: %v\n", MEMORY_FILE, err)
+		return
+	}
+	if err := json.Unmarshal(data, &memories); err != nil {
+		fmt.Printf("Error parsing `%s`: %v\n", MEMORY_FILE, err)
+	}
+}
+
+func saveMemories() {
+	data, err := json.MarshalIndent(memories, "", "  ")
+	if err != nil {
+		fmt.Printf("Error saving memories: %v\n", err)
+		return
+	}
+	if err := session.WriteSecureFile(MEMORY_FILE, data); err != nil {
+		fmt.Printf("Error writing `%s`: %v\n", MEMORY_FILE, err)
+	}
+}
+
+func remember(text string) {
+	memories = append(memories, Memory{Kind: MEMORY_FACT, Text: text, Created: time.Now()})
+	saveMemories()
+	fmt.Println("Remembered")
+}
+
+func listMemories() {
+	if len(memories) == 0 {
+		fmt.Println("No memories stored")
+		return
+	}
+	for i, memory := range memories {
+		text := memory.Text
+		if memory.IsSummary() {
+			text = "[session summary] " + strings.ReplaceAll(text, "\n", " ")
+		}
+		fmt.Printf("    %d. %s (%s)\n", i+1, text, memory.Created.Format(DATE_FORMAT))
+	}
+}
+
+func forgetMemory(arg string) {
+	if arg == "all" {
+		memories = nil
+		saveMemories()
+		fmt.Println("All memories forgotten")
+		return
+	}
+	idx, err := strconv.Atoi(arg)
+	if err != nil || idx < 1 || idx > len(memories) {
+		fmt.Printf("Error: `%s` is not a valid memory number\n", arg)
+		return
+	}
+	forgotten := memories[idx-1]
+	memories = append(memories[:idx-1], memories[idx:]...)
+	saveMemories()
+	fmt.Printf("Forgot `%s`\n", forgotten.Text)
+}
+
+// Stores the summary of the current session so it can be recalled later
+func rememberSession() {
+	if history.Summary == "" {
+		return
+	}
+	memories = append(memories, Memory{Kind: MEMORY_SUMMARY, Text: history.Summary, Created: time.Now()})
+	saveMemories()
+}
+
+// Selects the `MemoryRecall` memories closest to the question
+func recallMemories(client *openai.Client, config config.Config, question string) {
+	recalledMemories = nil
+	if config.MemoryRecall <= 0 || len(memories) == 0 {
+		return
+	}
+
+	model := embeddingModel(config)
+	missing := []int{}
+	texts := []string{question}
+	for i, memory := range memories {
+		if memory.Embedding == nil || memory.EmbeddingModel != model {
+			missing = append(missing, i)
+			texts = append(texts, memory.Text)
+		}
+	}
+	embeddings, err := embedTexts(client, model, texts)
+	if err != nil {
+		fmt.Printf("Error recalling memories: %v\n", err)
+		return
+	}
+	for i, idx := range missing {
+		memories[idx].Embedding = embeddings[i+1]
+		memories[idx].EmbeddingModel = model
+	}
+	if len(missing) > 0 {
+		saveMemories()
+	}
+
+	type scored struct {
+		Memory Memory
+		Score  float64
+	}
+	candidates := make([]scored, len(memories))
+	for i, memory := range memories {
+		candidates[i] = scored{Memory: memory, Score: cosineSimilarity(embeddings[0], memory.Embedding)}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	for i := 0; i < len(candidates) && i < config.MemoryRecall; i++ {
+		recalledMemories = append(recalledMemories, candidates[i].Memory)
+	}
+}
+
+// Without `MemoryRecall`, every fact is injected, but no session summary
+func memoryPrompt(config config.Config) string {
+	facts := []string{}
+	summaries := []string{}
+	if config.MemoryRecall > 0 {
+		for _, memory := range recalledMemories {
+			if memory.IsSummary() {
+				summaries = append(summaries, memory.Text)
+			} else {
+				facts = append(facts, memory.Text)
+			}
+		}
+	} else {
+		for _, memory := range memories {
+			if !memory.IsSummary() {
+				facts = append(facts, memory.Text)
+			}
+		}
+	}
+
+	sb := strings.Builder{}
+	if len(facts) > 0 {
+		sb.WriteString("Facts to remember about the user:\n")
+		for _, fact := range facts {
+			sb.WriteString("- ")
+			sb.WriteString(fact)
+			sb.WriteString("\n")
+		}
+	}
+	for _, summary := range summaries {
+		sb.WriteString("Summary of a previous conversation:\n")
+		sb.WriteString(summary)
+		sb.WriteString("\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
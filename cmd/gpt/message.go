@@ -0,0 +1,24 @@
+package main
+
+import (
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/session"
+)
+
+func newResponseMessage(content string, stats chat.ResponseStats) session.Message {
+	return session.Message{
+		Role:              openai.ChatMessageRoleAssistant,
+		Content:           content,
+		Time:              time.Now(),
+		Model:             stats.Model,
+		PromptTokens:      stats.PromptTokens,
+		CompletionTokens:  stats.CompletionTokens,
+		LatencyMs:         stats.Duration.Milliseconds(),
+		Seed:              stats.Seed,
+		SystemFingerprint: stats.SystemFingerprint,
+	}
+}
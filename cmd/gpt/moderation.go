@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+)
+
+// Values of Moderation, which content goes through the moderation endpoint
+const (
+	MODERATE_PROMPTS   = "prompts"
+	MODERATE_RESPONSES = "responses"
+	MODERATE_ALL       = "all"
+)
+
+// Values of ModerationAction
+const (
+	MODERATION_WARN  = "warn"
+	MODERATION_BLOCK = "block"
+)
+
+func shouldModerate(config config.Config, kind string) bool {
+	return config.Moderation == kind || config.Moderation == MODERATE_ALL
+}
+
+// Returns the flagged categories, e.g. `harassment` or `violence/graphic`
+func moderationCategories(client *openai.Client, text string) ([]string, error) {
+	ctx, cancel := chat.Context()
+	defer cancel()
+	resp, err := client.Moderations(ctx, openai.ModerationRequest{
+		Input: text,
+		Model: openai.ModerationOmniLatest,
+	})
+	if err != nil {
+		return nil, err
+	}
+	flagged := []string{}
+	for _, result := range resp.Results {
+		if !result.Flagged {
+			continue
+		}
+		data, err := json.Marshal(result.Categories)
+		if err != nil {
+			return nil, err
+		}
+		categories := map[string]bool{}
+		json.Unmarshal(data, &categories)
+		for category, set := range categories {
+			if set {
+				flagged = append(flagged, category)
+			}
+		}
+		// Categories the client doesn't know about
+		if len(flagged) == 0 {
+			flagged = append(flagged, "unknown")
+		}
+	}
+	sort.Strings(flagged)
+	return flagged, nil
+}
+
+// Returns false if the prompt or response (kind) should be blocked. When
+// blocking, content that can't be checked is blocked as well.
+func moderate(client *openai.Client, config config.Config, kind string, text string) bool {
+	if !shouldModerate(config, kind) {
+		return true
+	}
+	block := config.ModerationAction == MODERATION_BLOCK
+	categories, err := moderationCategories(client, text)
+	if err != nil {
+		fmt.Printf("Error checking content with the moderation endpoint: %v\n", err)
+		return !block
+	}
+	if len(categories) == 0 {
+		return true
+	}
+
+	subject := "Prompt"
+	if kind == MODERATE_RESPONSES {
+		subject = "Response"
+	}
+	if block {
+		fmt.Printf("%s blocked, flagged for: %s\n", subject, strings.Join(categories, ", "))
+		return false
+	}
+	fmt.Printf("Warning: %s flagged for: %s\n", strings.ToLower(subject), strings.Join(categories, ", "))
+	return true
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Request parameters overridden for a single message by inline directives,
+// e.g. `@temp=0 @model=gpt-4o-mini explain X`
+type Overrides struct {
+	Model       string
+	Temperature *float32
+	TopP        *float32
+	MaxTokens   int
+	Seed        *int
+}
+
+// Keys of the directives, messages starting with anything else, such as
+// `@alice` or `@user=x`, are sent unchanged
+var DIRECTIVES = []string{"model", "temp", "temperature", "top_p", "max_tokens", "seed"}
+
+// Splits the directives at the start of the line from the message
+func parseOverrides(line string) (Overrides, string, error) {
+	overrides := Overrides{}
+	rest := strings.TrimSpace(line)
+	for strings.HasPrefix(rest, "@") {
+		directive, after, _ := strings.Cut(rest, " ")
+		key, value, found := strings.Cut(directive[1:], "=")
+		if !found || !slices.Contains(DIRECTIVES, strings.ToLower(key)) {
+			break
+		}
+		if err := overrides.set(strings.ToLower(key), value); err != nil {
+			return overrides, "", err
+		}
+		rest = strings.TrimSpace(after)
+	}
+	if rest == strings.TrimSpace(line) {
+		return overrides, line, nil
+	}
+	if rest == "" {
+		return overrides, "", fmt.Errorf("expected a message after the directives")
+	}
+	return overrides, rest, nil
+}
+
+func (o *Overrides) set(key string, value string) error {
+	invalid := fmt.Errorf("invalid value `%s` for `@%s`", value, key)
+	switch key {
+	case "model":
+		o.Model = value
+	case "temp", "temperature", "top_p":
+		f, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return invalid
+		}
+		v := float32(f)
+		if key == "top_p" {
+			o.TopP = &v
+		} else {
+			o.Temperature = &v
+		}
+	case "max_tokens":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return invalid
+		}
+		o.MaxTokens = n
+	case "seed":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return invalid
+		}
+		o.Seed = &n
+	default:
+		return fmt.Errorf("unknown directive `@%s`, expected model, temp, top_p, max_tokens or seed", key)
+	}
+	return nil
+}
+
+// The client omits zero values, so a zero temperature or top_p is sent as
+// the smallest positive value instead
+func nonZero(f float32) float32 {
+	if f == 0 {
+		return math.SmallestNonzeroFloat32
+	}
+	return f
+}
+
+func (o Overrides) Apply(req *openai.ChatCompletionRequest) {
+	if o.Model != "" {
+		req.Model = o.Model
+	}
+	if o.Temperature != nil {
+		req.Temperature = nonZero(*o.Temperature)
+	}
+	if o.TopP != nil {
+		req.TopP = nonZero(*o.TopP)
+	}
+	if o.MaxTokens != 0 {
+		req.MaxTokens = o.MaxTokens
+	}
+	if o.Seed != nil {
+		req.Seed = o.Seed
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"gpt/session"
+)
+
+const DEFAULT_CAPTURE_LINES = 200
+
+// Returns the last lines of a terminal pane: with tmux the target is a pane
+// such as `{last}` or `1`, the current pane by default. iTerm2, WezTerm and
+// kitty (with remote control enabled) are supported outside of tmux.
+func capturePane(target string, lines int) (string, error) {
+	var cmd *exec.Cmd
+	switch {
+	case os.Getenv("TMUX") != "":
+		args := []string{"capture-pane", "-p", "-J", "-S", strconv.Itoa(-lines)}
+		if target != "" {
+			args = append(args, "-t", target)
+		}
+		cmd = exec.Command("tmux", args...)
+	case os.Getenv("TERM_PROGRAM") == "iTerm.app":
+		cmd = exec.Command("osascript", "-e", `tell application "iTerm2" to tell current session of current window to get contents`)
+	case os.Getenv("TERM_PROGRAM") == "WezTerm":
+		cmd = exec.Command("wezterm", "cli", "get-text", "--start-line", strconv.Itoa(-lines))
+	case os.Getenv("KITTY_WINDOW_ID") != "":
+		cmd = exec.Command("kitty", "@", "get-text", "--extent", "all")
+	default:
+		return "", fmt.Errorf("capturing the pane requires tmux, iTerm2, WezTerm or kitty")
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("%s: %s", cmd.Args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	// Terminals without a line range give the whole scrollback
+	captured := strings.Split(strings.TrimRight(string(out), "\n "), "\n")
+	if len(captured) > lines {
+		captured = captured[len(captured)-lines:]
+	}
+	return strings.Join(captured, "\n"), nil
+}
+
+// Handles `/capture-pane [target] [lines]`
+func capturePaneCommand(args []string) bool {
+	target, lines := "", DEFAULT_CAPTURE_LINES
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil && n > 0 {
+			lines = n
+		} else if target == "" {
+			target = arg
+		} else {
+			return false
+		}
+	}
+	content, err := capturePane(target, lines)
+	if err != nil {
+		fmt.Printf("Error capturing the pane: %v\n", err)
+		return true
+	}
+	name := "pane"
+	if target != "" {
+		name += " " + target
+	}
+	addContextItem(session.ContextItem{Name: name, Kind: session.CONTEXT_PANE, Content: content})
+	fmt.Printf("Added the last %d lines of the %s to the system prompt\n", len(strings.Split(content, "\n")), name)
+	return true
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/atotto/clipboard"
+
+	"gpt/render"
+)
+
+// Ctrl-V, which readline leaves unbound
+const PASTE_KEY = 22
+
+var (
+	// Multi-line pastes are shown as placeholders in the line being edited
+	// and expanded when it is sent
+	pastes      []string
+	pasteRe     = regexp.MustCompile(`\[paste #(\d+), \d+ lines\]`)
+	codeLineRe  = regexp.MustCompile(`^\s+\S|[;{}()\[\]]\s*$|^\s*(func|def|class|import|from|package|return|if|for|while|const|let|var|fn|pub|#include|//|#!)\b`)
+	draftPrompt string
+)
+
+// Most lines of code are indented, end with punctuation or start with a keyword
+func looksLikeCode(text string) bool {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) < 2 {
+		return false
+	}
+	code := 0
+	for _, line := range lines {
+		if codeLineRe.MatchString(line) {
+			code++
+		}
+	}
+	return code*2 >= len(lines)
+}
+
+func fenceCode(text string) string {
+	return "```" + render.GuessLanguage(text) + "\n" + strings.Trim(text, "\n") + "\n```"
+}
+
+// Code that is already in a code block is left as is
+func needsFence(text string) bool {
+	return looksLikeCode(text) && !strings.Contains(text, "```")
+}
+
+// Reads the clipboard, fenced when it looks like code
+func clipboardText() (string, error) {
+	content, err := clipboard.ReadAll()
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("the clipboard is empty")
+	}
+	if needsFence(content) {
+		return fenceCode(content), nil
+	}
+	return content, nil
+}
+
+// Stores a paste and returns its placeholder
+func addPaste(text string) string {
+	pastes = append(pastes, text)
+	return fmt.Sprintf("[paste #%d, %d lines]", len(pastes), strings.Count(text, "\n")+1)
+}
+
+func expandPastes(line string) string {
+	if len(pastes) == 0 {
+		return line
+	}
+	line = pasteRe.ReplaceAllStringFunc(line, func(placeholder string) string {
+		n, _ := strconv.Atoi(pasteRe.FindStringSubmatch(placeholder)[1])
+		if n < 1 || n > len(pastes) {
+			return placeholder
+		}
+		return "\n" + pastes[n-1] + "\n"
+	})
+	pastes = nil
+	return strings.TrimSpace(line)
+}
+
+// Inserts the clipboard at the cursor when PASTE_KEY is pressed
+func pasteListener(line []rune, pos int, key rune) ([]rune, int, bool) {
+	if key != PASTE_KEY || pos == 0 {
+		return nil, 0, false
+	}
+	// The key itself was inserted before the cursor
+	line = append(line[:pos-1:pos-1], line[pos:]...)
+	pos--
+	text, err := clipboardText()
+	if err != nil {
+		return line, pos, true
+	}
+	if strings.Contains(text, "\n") {
+		text = addPaste(text)
+	}
+	inserted := []rune(text)
+	line = append(line[:pos:pos], append(inserted, line[pos:]...)...)
+	return line, pos + len(inserted), true
+}
+
+// Handles `/paste [message]`: sends the message with the clipboard, or puts
+// the clipboard in the next prompt to write the message around it
+func pasteCommand(args []string) {
+	text, err := clipboardText()
+	if err != nil {
+		fmt.Printf("Error reading clipboard: %v\n", err)
+		return
+	}
+	if len(args) > 0 {
+		pendingInput = strings.Join(args, " ") + "\n\n" + text
+		return
+	}
+	if !strings.Contains(text, "\n") {
+		draftPrompt = text
+		return
+	}
+	draftPrompt = addPaste(text) + " "
+}
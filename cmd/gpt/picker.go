@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/chzyer/readline"
+
+	"gpt/config"
+	"gpt/session"
+)
+
+func formatAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// Reads a single key press, without waiting for Enter
+func readKey() (byte, error) {
+	fd := readline.GetStdin()
+	state, err := readline.MakeRaw(fd)
+	if err != nil {
+		return 0, err
+	}
+	defer readline.Restore(fd, state)
+
+	buf := make([]byte, 1)
+	if _, err := os.Stdin.Read(buf); err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+// Offers to resume one of the `RecentSessions` most recent sessions. Must run
+// before readline takes over the terminal.
+func pickRecentSession(config *config.Config) {
+	if config.RecentSessions <= 0 || !readline.DefaultIsTerminal() {
+		return
+	}
+	sessions, err := session.List()
+	if err != nil || len(sessions) == 0 {
+		return
+	}
+	// Sessions are picked with a single digit
+	count := min(config.RecentSessions, len(sessions), 9)
+
+	fmt.Println("Recent sessions:")
+	for i, sess := range sessions[:count] {
+		fmt.Printf("    %d. %s (%s)\n", i+1, sess.DisplayName(), formatAge(sess.Updated))
+	}
+	fmt.Printf("Press 1-%d to resume a session, any other key to start fresh\n", count)
+
+	key, err := readKey()
+	if err != nil || key < '1' || key > byte('0'+count) {
+		return
+	}
+	sess := sessions[key-'1']
+	resumeSession(sess, config)
+	fmt.Printf("Resumed `%s` (%d messages)\n", sess.DisplayName(), len(history.Messages))
+}
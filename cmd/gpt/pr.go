@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/render"
+)
+
+const PR_PROMPT = "Write a pull request for the following changes. Answer with the title alone on the first line, " +
+	"then a blank line and a Markdown description with these sections: `## Summary` (what the change does and why), " +
+	"`## Changes` (a bullet list of notable changes) and `## Changelog` (user-facing entries, or `None`). Don't wrap the answer in code fences."
+
+// The default branch of origin, or main/master when it is unknown
+func baseBranch() (string, error) {
+	if ref, err := git("symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimSpace(ref), nil
+	}
+	for _, branch := range []string{"origin/main", "origin/master", "main", "master"} {
+		if _, err := git("rev-parse", "--verify", "--quiet", branch); err == nil {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("can't find the base branch, pass it as an argument")
+}
+
+// Handles `/pr [base]`: describes the commits of the current branch that are
+// not in the base, then copies the result or creates the PR with gh
+func prCommand(client *openai.Client, config config.Config, base string) {
+	if _, err := git("rev-parse", "--is-inside-work-tree"); err != nil {
+		fmt.Println("Error: not in a git repository")
+		return
+	}
+	if base == "" {
+		var err error
+		if base, err = baseBranch(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+	commits, err := git("log", "--reverse", "--format=- %s%n%b", base+"..HEAD")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if strings.TrimSpace(commits) == "" {
+		fmt.Printf("No commits on this branch that are not in `%s`\n", base)
+		return
+	}
+	stat, _ := git("diff", "--stat", base+"...HEAD")
+	diff, err := git("diff", base+"...HEAD")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if !checkBudget(config) {
+		return
+	}
+
+	input := fmt.Sprintf("Commits:\n%s\nStat:\n%s\nDiff:\n%s", commits, stat, truncateDiff(diff))
+	pr, err := chat.Complete(client, config.Model, PR_PROMPT, input)
+	if err != nil {
+		fmt.Printf("Error generating the pull request: %v\n", err)
+		return
+	}
+	title, body, _ := strings.Cut(pr, "\n")
+	title = strings.TrimSpace(strings.TrimLeft(title, "# "))
+	body = strings.TrimSpace(body)
+	fmt.Printf("\n%s\n\n", title)
+	render.Markdown(config, body)
+
+	answer, _ := ask(i18n.T("[c]opy, [p]ost with gh, [N]othing "))
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "c", "copy":
+		if err := writeClipboard(title+"\n\n"+body, COPY_MARKDOWN); err != nil {
+			fmt.Printf("Error copying to clipboard: %v\n", err)
+			return
+		}
+		fmt.Println("Copied to clipboard")
+	case "p", "post":
+		postPullRequest(title, body, base)
+	}
+}
+
+func postPullRequest(title string, body string, base string) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		fmt.Println("Error: the gh CLI is not installed")
+		return
+	}
+	file, err := os.CreateTemp("", "gpt-pr-*.md")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer os.Remove(file.Name())
+	file.WriteString(body + "\n")
+	file.Close()
+
+	// gh expects the branch name, without the remote
+	base = strings.TrimPrefix(base, "origin/")
+	cmd := exec.Command("gh", "pr", "create", "--title", title, "--body-file", file.Name(), "--base", base)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = terminalStdout()
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Error creating the pull request: %v\n", err)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"gpt/config"
+)
+
+const (
+	PREVIEW_AUTO  = "auto"
+	PREVIEW_KITTY = "kitty"
+	PREVIEW_ITERM = "iterm"
+	PREVIEW_SIXEL = "sixel"
+	PREVIEW_OPEN  = "open"
+	PREVIEW_NONE  = "none"
+	// Images are scaled down to this width for sixel output
+	SIXEL_MAX_WIDTH = 512
+)
+
+// Guesses the inline image protocol of the terminal from its environment
+func detectImageProtocol() string {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || program == "ghostty":
+		return PREVIEW_KITTY
+	case program == "iTerm.app" || program == "WezTerm" || os.Getenv("LC_TERMINAL") == "iTerm2":
+		return PREVIEW_ITERM
+	case strings.Contains(term, "sixel") || program == "mlterm" || strings.HasPrefix(term, "foot") || os.Getenv("WT_SESSION") != "":
+		return PREVIEW_SIXEL
+	}
+	return PREVIEW_OPEN
+}
+
+func previewImage(config config.Config, path string) {
+	mode := config.ImagePreview
+	if mode == "" || mode == PREVIEW_AUTO {
+		mode = detectImageProtocol()
+	}
+
+	var err error
+	switch mode {
+	case PREVIEW_NONE:
+		return
+	case PREVIEW_KITTY:
+		err = printKittyImage(path)
+	case PREVIEW_ITERM:
+		err = printITermImage(path)
+	case PREVIEW_SIXEL:
+		err = printSixelImage(path)
+	case PREVIEW_OPEN:
+		err = openFile(path)
+	default:
+		err = fmt.Errorf("unknown ImagePreview mode `%s`", mode)
+	}
+	if err != nil {
+		fmt.Printf("Error previewing `%s`: %v\n", path, err)
+	}
+}
+
+// Opens a file with the default application of the OS
+func openFile(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	return cmd.Start()
+}
+
+// https://sw.kovidgoyal.net/kitty/graphics-protocol/, PNG data is sent in
+// chunks of at most 4096 bytes
+func printKittyImage(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	first := true
+	for len(encoded) > 0 {
+		chunk := encoded[:min(4096, len(encoded))]
+		encoded = encoded[len(chunk):]
+		more := 0
+		if len(encoded) > 0 {
+			more = 1
+		}
+		if first {
+			fmt.Printf("\x1b_Gf=100,a=T,m=%d;%s\x1b\\", more, chunk)
+			first = false
+		} else {
+			fmt.Printf("\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// https://iterm2.com/documentation-images.html
+func printITermImage(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\x1b]1337;File=inline=1;size=%d;preserveAspectRatio=1:%s\a\n", len(data), base64.StdEncoding.EncodeToString(data))
+	return nil
+}
+
+func printSixelImage(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return err
+	}
+	os.Stdout.Write(encodeSixel(img))
+	fmt.Println()
+	return nil
+}
+
+func encodeSixel(img image.Image) []byte {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > SIXEL_MAX_WIDTH {
+		height = height * SIXEL_MAX_WIDTH / width
+		width = SIXEL_MAX_WIDTH
+	}
+
+	// Nearest-neighbor scaling, then dithering to a 256 colors palette
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			scaled.Set(x, y, img.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height))
+		}
+	}
+	paletted := image.NewPaletted(scaled.Bounds(), palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, scaled.Bounds(), scaled, image.Point{})
+
+	buf := bytes.Buffer{}
+	buf.WriteString(fmt.Sprintf("\x1bPq\"1;1;%d;%d", width, height))
+	for i, c := range paletted.Palette {
+		r, g, b, _ := c.RGBA()
+		buf.WriteString(fmt.Sprintf("#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff))
+	}
+
+	// Every sixel character encodes a column of 6 pixels
+	for top := 0; top < height; top += 6 {
+		used := map[uint8]bool{}
+		for y := top; y < min(top+6, height); y++ {
+			for x := range width {
+				used[paletted.ColorIndexAt(x, y)] = true
+			}
+		}
+		for c := range used {
+			buf.WriteString(fmt.Sprintf("#%d", c))
+			var run byte
+			count := 0
+			flush := func() {
+				if count > 3 {
+					buf.WriteString(fmt.Sprintf("!%d%c", count, run))
+				} else {
+					buf.WriteString(strings.Repeat(string(run), count))
+				}
+			}
+			for x := range width {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < height; dy++ {
+					if paletted.ColorIndexAt(x, top+dy) == c {
+						bits |= 1 << dy
+					}
+				}
+				char := bits + 63
+				if count > 0 && char == run {
+					count++
+					continue
+				}
+				if count > 0 {
+					flush()
+				}
+				run, count = char, 1
+			}
+			flush()
+			buf.WriteByte('$')
+		}
+		buf.WriteByte('-')
+	}
+	buf.WriteString("\x1b\\")
+	return buf.Bytes()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"gpt/config"
+	"gpt/provider"
+	"gpt/render"
+)
+
+func printPricing(config config.Config, model string) {
+	if model != "" {
+		price, ok := provider.LookupPrice(model)
+		if !ok {
+			fmt.Printf("No price known for `%s`, add it to the `Pricing` table of the config\n", model)
+			return
+		}
+		fmt.Printf("%s: $%.2f / 1M input tokens, $%.2f / 1M output tokens\n", model, price.Input, price.Output)
+		return
+	}
+
+	models := make([]string, 0, len(provider.Prices))
+	for name := range provider.Prices {
+		models = append(models, name)
+	}
+	sort.Strings(models)
+
+	rows := [][]string{}
+	for _, name := range models {
+		price := provider.Prices[name]
+		source := "built-in"
+		if _, ok := config.Pricing[name]; ok {
+			source = "config"
+		}
+		rows = append(rows, []string{
+			name,
+			fmt.Sprintf("$%.2f", price.Input),
+			fmt.Sprintf("$%.2f", price.Output),
+			source,
+		})
+	}
+	fmt.Println("Prices per 1M tokens:")
+	render.Table([]string{"Model", "Input", "Output", "Source"}, rows)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"gpt/i18n"
+	"gpt/render"
+	"gpt/session"
+)
+
+const QUEUE_FILE = "gpt_queue.json"
+
+// Prompts that couldn't be sent because the network was down
+type QueuedPrompt struct {
+	Prompt string    `json:"prompt"`
+	Queued time.Time `json:"queued"`
+}
+
+var (
+	// Prompts being replayed, sent one after the other as if typed
+	replayQueue []string
+	// Replaying is only offered once per session
+	replayOffered bool
+)
+
+// Network errors, as opposed to errors returned by the API
+func isOffline(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func loadQueue() []QueuedPrompt {
+	queue := []QueuedPrompt{}
+	data, err := session.ReadSecureFile(QUEUE_FILE)
+	if os.IsNotExist(err) {
+		return queue
+	}
+	if err != nil {
+		fmt.Printf("Error reading `%s`: %v\n", QUEUE_FILE, err)
+		return queue
+	}
+	if err := json.Unmarshal(data, &queue); err != nil {
+		fmt.Printf("Error parsing `%s`: %v\n", QUEUE_FILE, err)
+	}
+	return queue
+}
+
+func saveQueue(queue []QueuedPrompt) {
+	if len(queue) == 0 {
+		os.Remove(QUEUE_FILE)
+		return
+	}
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		fmt.Printf("Error saving the queue: %v\n", err)
+		return
+	}
+	if err := session.WriteSecureFile(QUEUE_FILE, data); err != nil {
+		fmt.Printf("Error writing `%s`: %v\n", QUEUE_FILE, err)
+	}
+}
+
+// Offers to queue a prompt that failed with a network error
+func offerQueue(prompt string, err error) {
+	fmt.Printf("Error: the API can't be reached: %v\n", err)
+	if !confirm(i18n.T("Queue the message to send it later?")) {
+		return
+	}
+	queue := append(loadQueue(), QueuedPrompt{Prompt: prompt, Queued: time.Now()})
+	saveQueue(queue)
+	fmt.Printf("Queued, %d messages waiting. Use `/queue flush` once you are back online\n", len(queue))
+}
+
+func printQueue() {
+	queue := loadQueue()
+	if len(queue) == 0 {
+		fmt.Println("No queued messages")
+		return
+	}
+	rows := [][]string{}
+	for i, queued := range queue {
+		rows = append(rows, []string{fmt.Sprint(i + 1), queued.Queued.Format("2006-01-02 15:04"), preview(queued.Prompt, 60)})
+	}
+	render.Table([]string{"#", "Queued", "Message"}, rows)
+}
+
+// Asks to confirm every queued prompt, the confirmed ones are sent next and
+// the others stay in the queue
+func flushQueue() {
+	queue := loadQueue()
+	if len(queue) == 0 {
+		fmt.Println("No queued messages")
+		return
+	}
+	kept := []QueuedPrompt{}
+	for _, queued := range queue {
+		if confirm(i18n.T("Send \"%s\"?", preview(queued.Prompt, 60))) {
+			replayQueue = append(replayQueue, queued.Prompt)
+		} else {
+			kept = append(kept, queued)
+		}
+	}
+	saveQueue(kept)
+}
+
+// Called once a request succeeds, as the network is back
+func offerReplay() {
+	if replayOffered {
+		return
+	}
+	replayOffered = true
+	queue := loadQueue()
+	if len(queue) == 0 || !confirm(i18n.T("You are back online, replay the %d queued messages?", len(queue))) {
+		return
+	}
+	flushQueue()
+}
+
+// Returns the next prompt to replay, if any
+func nextReplay() (string, bool) {
+	if len(replayQueue) == 0 {
+		return "", false
+	}
+	prompt := replayQueue[0]
+	replayQueue = replayQueue[1:]
+	return prompt, true
+}
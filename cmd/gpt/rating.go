@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gpt/session"
+)
+
+type Exchange struct {
+	Prompt       string
+	Response     string
+	Model        string
+	SystemPrompt string
+}
+
+// The last prompt / response pair, which `/rate` applies to
+var lastExchange *Exchange
+
+type Rating struct {
+	Time         time.Time `json:"time"`
+	Rating       int       `json:"rating"`
+	Comment      string    `json:"comment,omitempty"`
+	Model        string    `json:"model"`
+	SystemPrompt string    `json:"system_prompt"`
+	Prompt       string    `json:"prompt"`
+	Response     string    `json:"response"`
+}
+
+// Accepts a 1-5 score, or `up` / `down` (stored as 5 and 1)
+func parseRating(arg string) (int, bool) {
+	switch strings.ToLower(arg) {
+	case "up", "+":
+		return 5, true
+	case "down", "-":
+		return 1, true
+	}
+	rating, err := strconv.Atoi(arg)
+	if err != nil || rating < 1 || rating > 5 {
+		return 0, false
+	}
+	return rating, true
+}
+
+func rateLastResponse(rating int, comment string) {
+	if lastExchange == nil {
+		fmt.Println("Nothing to rate!")
+		return
+	}
+
+	data, err := json.Marshal(Rating{
+		Time:         time.Now(),
+		Rating:       rating,
+		Comment:      comment,
+		Model:        lastExchange.Model,
+		SystemPrompt: lastExchange.SystemPrompt,
+		Prompt:       lastExchange.Prompt,
+		Response:     lastExchange.Response,
+	})
+	if err != nil {
+		fmt.Printf("Error saving rating: %v\n", err)
+		return
+	}
+	if err := session.AppendSecureFile(RATINGS_FILE, append(data, '\n')); err != nil {
+		fmt.Printf("Error writing `%s`: %v\n", RATINGS_FILE, err)
+		return
+	}
+	fmt.Printf("Rated %d/5 (%s)\n", rating, lastExchange.Model)
+}
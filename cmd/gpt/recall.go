@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/config"
+	"gpt/provider"
+	"gpt/session"
+)
+
+const (
+	// Inputs longer than this are truncated before being embedded
+	MAX_CHUNK_LENGTH = 6000
+	RECALL_RESULTS   = 5
+)
+
+type indexedChunk struct {
+	Message   int       `json:"message"`
+	Text      string    `json:"text"`
+	Embedding []float32 `json:"embedding"`
+}
+
+type indexedSession struct {
+	Updated time.Time      `json:"updated"`
+	Model   string         `json:"model"`
+	Chunks  []indexedChunk `json:"chunks"`
+}
+
+type recallResult struct {
+	Session *session.Session
+	Chunk   indexedChunk
+	Score   float64
+}
+
+// Results of the last `/recall` search, referenced by `/recall load | quote`
+var recallResults []recallResult
+
+func loadSessionIndex() map[string]*indexedSession {
+	index := map[string]*indexedSession{}
+	data, err := session.ReadSecureFile(session.INDEX_FILE)
+	if err != nil {
+		return index
+	}
+	json.Unmarshal(data, &index)
+	return index
+}
+
+func saveSessionIndex(index map[string]*indexedSession) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return session.WriteSecureFile(session.INDEX_FILE, data)
+}
+
+// Each user message and the answer to it form a chunk
+func chunkSession(sess *session.Session) []indexedChunk {
+	chunks := []indexedChunk{}
+	for i, msg := range sess.Messages {
+		if msg.Role != openai.ChatMessageRoleUser {
+			continue
+		}
+		text := "user: " + msg.Content
+		if i+1 < len(sess.Messages) && sess.Messages[i+1].Role == openai.ChatMessageRoleAssistant {
+			text += "\nassistant: " + sess.Messages[i+1].Content
+		}
+		if len(text) > MAX_CHUNK_LENGTH {
+			text = truncate(text, MAX_CHUNK_LENGTH)
+		}
+		chunks = append(chunks, indexedChunk{Message: i, Text: text})
+	}
+	return chunks
+}
+
+// Embeds the sessions that changed since they were last indexed
+func updateSessionIndex(client *openai.Client, config config.Config, sessions []*session.Session) (map[string]*indexedSession, error) {
+	model := embeddingModel(config)
+	index := loadSessionIndex()
+	stale := []*session.Session{}
+	for _, sess := range sessions {
+		indexed, ok := index[sess.ID]
+		if !ok || indexed.Model != model || sess.Updated.After(indexed.Updated) {
+			stale = append(stale, sess)
+		}
+	}
+
+	// Sessions are embedded `Concurrency` at a time
+	indexed := make([]*indexedSession, len(stale))
+	errs := make([]error, len(stale))
+	provider.RunPool(provider.Concurrency(config), len(stale), func(i int) {
+		chunks := chunkSession(stale[i])
+		if len(chunks) > 0 {
+			texts := make([]string, len(chunks))
+			for j, chunk := range chunks {
+				texts[j] = chunk.Text
+			}
+			embeddings, err := embedTexts(client, model, texts)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for j := range chunks {
+				chunks[j].Embedding = embeddings[j]
+			}
+		}
+		indexed[i] = &indexedSession{Updated: stale[i].Updated, Model: model, Chunks: chunks}
+	})
+	for i, sess := range stale {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		index[sess.ID] = indexed[i]
+	}
+	if len(stale) > 0 {
+		if err := saveSessionIndex(index); err != nil {
+			fmt.Printf("Error saving `%s`: %v\n", session.INDEX_FILE, err)
+		}
+	}
+	return index, nil
+}
+
+func recallConversations(client *openai.Client, config config.Config, query string) {
+	sessions, err := session.List()
+	if err != nil {
+		fmt.Printf("Error listing sessions: %v\n", err)
+		return
+	}
+	index, err := updateSessionIndex(client, config, sessions)
+	if err != nil {
+		fmt.Printf("Error indexing sessions: %v\n", err)
+		return
+	}
+	embeddings, err := embedTexts(client, embeddingModel(config), []string{query})
+	if err != nil {
+		fmt.Printf("Error embedding query: %v\n", err)
+		return
+	}
+
+	recallResults = nil
+	for _, sess := range sessions {
+		indexed, ok := index[sess.ID]
+		if !ok || sess.ID == currentSession.ID {
+			continue
+		}
+		// Only the best matching chunk of every session is kept
+		best := recallResult{Score: -1}
+		for _, chunk := range indexed.Chunks {
+			score := cosineSimilarity(embeddings[0], chunk.Embedding)
+			if score > best.Score {
+				best = recallResult{Session: sess, Chunk: chunk, Score: score}
+			}
+		}
+		if best.Session != nil {
+			recallResults = append(recallResults, best)
+		}
+	}
+	sort.Slice(recallResults, func(i, j int) bool {
+		return recallResults[i].Score > recallResults[j].Score
+	})
+	if len(recallResults) > RECALL_RESULTS {
+		recallResults = recallResults[:RECALL_RESULTS]
+	}
+
+	if len(recallResults) == 0 {
+		fmt.Println("No matching conversation found")
+		return
+	}
+	for i, result := range recallResults {
+		snippet := strings.ReplaceAll(result.Chunk.Text, "\n", " ")
+		if len(snippet) > 100 {
+			snippet = truncate(snippet, 100) + "..."
+		}
+		fmt.Printf("    %d. %s (%s, %.2f)\n       %s\n", i+1, result.Session.DisplayName(), result.Session.Updated.Format(DATE_FORMAT), result.Score, snippet)
+	}
+	fmt.Printf("Use `%srecall load <n>` to continue a conversation, or `%srecall quote <n>` to add the excerpt to the system prompt\n", config.CommandPrefix, config.CommandPrefix)
+}
+
+func recallResultAt(arg string) (recallResult, bool) {
+	idx, err := strconv.Atoi(arg)
+	if err != nil || idx < 1 || idx > len(recallResults) {
+		fmt.Printf("Error: `%s` is not a valid result number\n", arg)
+		return recallResult{}, false
+	}
+	return recallResults[idx-1], true
+}
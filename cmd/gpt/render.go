@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/session"
+)
+
+// Returns the nth most recent assistant message of the session, starting at 1
+func lastAssistantMessage(n int) (string, bool) {
+	for i := len(currentSession.Messages) - 1; i >= 0; i-- {
+		if currentSession.Messages[i].Role != openai.ChatMessageRoleAssistant {
+			continue
+		}
+		n--
+		if n == 0 {
+			return currentSession.Messages[i].Content, true
+		}
+	}
+	return "", false
+}
+
+// Messages are numbered from 1, in the order of the session transcript
+func messageAt(arg string) (session.Message, bool) {
+	idx, err := strconv.Atoi(arg)
+	if err != nil || idx < 1 || idx > len(currentSession.Messages) {
+		fmt.Printf("Error: `%s` is not a valid message number (1-%d)\n", arg, len(currentSession.Messages))
+		return session.Message{}, false
+	}
+	return currentSession.Messages[idx-1], true
+}
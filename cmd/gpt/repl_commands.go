@@ -0,0 +1,532 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gpt/commands"
+	"gpt/i18n"
+	"gpt/render"
+	"gpt/session"
+)
+
+// TODO: add shortcuts (/q, /h, ...)
+// TODO: add /summary
+// TODO: add /export html | md
+func init() {
+	commands.Register("system", []string{"show", "reset"}, "Manipulate the system prompt", func(ctx *commands.Context) {
+		if len(ctx.Args) != 2 {
+			fmt.Printf("Error: `%ssystem <option>` command expects `show`, or `reset`\n", ctx.Config.CommandPrefix)
+			return
+		}
+		switch ctx.Args[1] {
+		case "show":
+			fmt.Println(systemPrompt(*ctx.Config))
+		case "reset":
+			ctx.Config.SystemPrompt = defaultSystemPrompt
+			fmt.Println("System prompt has been reset")
+		}
+	})
+	commands.Register("embed", []string{"file", "list", "watch", "unwatch", "remove", "clear"}, "Embed a file (or file:120-200, file.go#Func), a directory, `-` (paste) or `clipboard`, or manage embedded content", func(ctx *commands.Context) {
+		if len(ctx.Args) < 2 {
+			fmt.Printf("Error: `%sembed <file>` command expects at least a file name\n", ctx.Config.CommandPrefix)
+			return
+		}
+
+		switch ctx.Args[1] {
+		case "remove":
+			if len(ctx.Args) < 3 {
+				fmt.Printf("Error: `%sembed remove <name>` command expects at least a name\n", ctx.Config.CommandPrefix)
+				return
+			}
+			for _, name := range ctx.Args[2:] {
+				removeContextItem(name)
+			}
+		case "list":
+			printContextItems(*ctx.Config)
+		case "watch", "unwatch":
+			if len(ctx.Args) < 3 {
+				fmt.Printf("Error: `%sembed %s <file>` command expects at least a file name\n", ctx.Config.CommandPrefix, ctx.Args[1])
+				return
+			}
+			for _, name := range ctx.Args[2:] {
+				watchContextItem(name, ctx.Args[1] == "watch")
+			}
+		case "clear":
+			contextItems = nil
+			fmt.Println("Removed all embedded content from system prompt")
+		default:
+			for _, path := range ctx.Args[1:] {
+				embedPath(ctx.Client, ctx.Config, path)
+			}
+		}
+	})
+	commands.Register("save", []string{"path"}, "Save the history to <path> (JSON format)", saveOrLoadCommand)
+	commands.Register("load", []string{"path"}, "Load the history from <path> (JSON format)", saveOrLoadCommand)
+	commands.Register("compare", []string{"prompt"}, "Send <prompt> to every model of `CompareModels` in parallel", func(ctx *commands.Context) {
+		prompt := ctx.Rest
+		if prompt == "" {
+			fmt.Printf("Error: `%scompare \"<prompt>\"` command expects a prompt\n", ctx.Config.CommandPrefix)
+			return
+		}
+		prompt, ok := filterPrompt(prompt)
+		if !ok {
+			return
+		}
+		if len(ctx.Config.CompareModels) == 0 {
+			fmt.Println("Error: no models to compare, set `CompareModels` in the config")
+			return
+		}
+		if !checkBudget(*ctx.Config) || !moderate(ctx.Client, *ctx.Config, MODERATE_PROMPTS, prompt) {
+			return
+		}
+		recallMemories(ctx.Client, *ctx.Config, prompt)
+		response := runCompare(ctx.Client, *ctx.Config, prompt)
+		if response == "" {
+			return
+		}
+		chatResponse.Reset()
+		chatResponse.WriteString(response)
+		autoCopy(*ctx.Config, response)
+		lastExchange = &Exchange{
+			Prompt:       prompt,
+			Response:     response,
+			Model:        strings.Join(ctx.Config.CompareModels, ","),
+			SystemPrompt: ctx.Config.SystemPrompt,
+		}
+		maybeGenerateTitle(ctx.Client, *ctx.Config, prompt, response)
+		compactHistory(ctx.Client, *ctx.Config)
+		archiveSession(*ctx.Config)
+	})
+	commands.Register("last", []string{"n"}, "Show the last (or <n>th most recent) response again", func(ctx *commands.Context) {
+		if len(ctx.Args) > 2 {
+			fmt.Printf("Usage: %slast [n]\n", ctx.Config.CommandPrefix)
+			return
+		}
+		n := 1
+		if len(ctx.Args) == 2 {
+			var err error
+			n, err = strconv.Atoi(ctx.Args[1])
+			if err != nil || n < 1 {
+				fmt.Printf("Error: `%s` is not a valid number\n", ctx.Args[1])
+				return
+			}
+		}
+		response, ok := lastAssistantMessage(n)
+		if !ok {
+			fmt.Println("No such response!")
+			return
+		}
+		render.Markdown(*ctx.Config, response)
+	})
+	commands.Register("history", []string{"n"}, "List the last <n> messages of the conversation with their numbers", func(ctx *commands.Context) {
+		if len(ctx.Args) > 2 {
+			fmt.Printf("Usage: %shistory [n]\n", ctx.Config.CommandPrefix)
+			return
+		}
+		n := DEFAULT_HISTORY_COUNT
+		if len(ctx.Args) == 2 {
+			var err error
+			n, err = strconv.Atoi(ctx.Args[1])
+			if err != nil || n < 1 {
+				fmt.Printf("Error: `%s` is not a valid number\n", ctx.Args[1])
+				return
+			}
+		}
+		printHistory(n)
+	})
+	commands.Register("grep", []string{"regex"}, "Search the conversation for lines matching <regex>", func(ctx *commands.Context) {
+		pattern := ctx.Rest
+		if pattern == "" {
+			fmt.Printf("Error: `%sgrep <regex>` command expects a regex\n", ctx.Config.CommandPrefix)
+			return
+		}
+		grepHistory(pattern)
+	})
+	commands.Register("render", []string{"n"}, "Render message <n> of the conversation as Markdown", func(ctx *commands.Context) {
+		if len(ctx.Args) != 2 {
+			fmt.Printf("Usage: %srender <n>\n", ctx.Config.CommandPrefix)
+			return
+		}
+		msg, ok := messageAt(ctx.Args[1])
+		if !ok {
+			return
+		}
+		fmt.Printf("--- %s ---\n", msg.Role)
+		render.Markdown(*ctx.Config, msg.Content)
+	})
+	commands.Register("export", []string{"bundle"}, "Export the session, prompts and config as a bundle to <path>", func(ctx *commands.Context) {
+		if len(ctx.Args) < 2 || len(ctx.Args) > 3 || ctx.Args[1] != "bundle" {
+			fmt.Printf("Usage: %sexport bundle [path]\n", ctx.Config.CommandPrefix)
+			return
+		}
+		path := slugify(currentSession.DisplayName()) + ".zip"
+		if len(ctx.Args) == 3 {
+			path = ctx.Args[2]
+		}
+		exportBundle(*ctx.Config, path)
+	})
+	commands.Register("import", []string{"bundle"}, "Import a session bundle from <path>", func(ctx *commands.Context) {
+		if len(ctx.Args) != 3 || ctx.Args[1] != "bundle" {
+			fmt.Printf("Usage: %simport bundle <path>\n", ctx.Config.CommandPrefix)
+			return
+		}
+		archiveSession(*ctx.Config)
+		loadBundle(ctx.Config, ctx.Args[2])
+	})
+	commands.Register("imagine", []string{"prompt"}, "Generate an image from <prompt>", func(ctx *commands.Context) {
+		prompt := ctx.Rest
+		if prompt == "" {
+			fmt.Printf("Error: `%simagine \"<prompt>\"` command expects a prompt\n", ctx.Config.CommandPrefix)
+			return
+		}
+		for _, path := range imagine(ctx.Client, *ctx.Config, prompt) {
+			previewImage(*ctx.Config, path)
+		}
+	})
+	commands.Register("imgedit", []string{"path"}, "Edit an image following \"<instructions>\" (optionally --mask <mask>), or create a variation", func(ctx *commands.Context) {
+		path, mask, instructions, ok := parseImageEditArgs(ctx.Rest)
+		if !ok {
+			fmt.Printf("Usage: %simgedit <path> [--mask <mask>] [\"<instructions>\"]\n", ctx.Config.CommandPrefix)
+			return
+		}
+		for _, path := range editImage(ctx.Client, *ctx.Config, path, mask, instructions) {
+			previewImage(*ctx.Config, path)
+		}
+	})
+	commands.Register("transcribe", []string{"file"}, "Transcribe an audio file, add it as context with --context or send it with --send", func(ctx *commands.Context) {
+		if !transcribeCommand(ctx.Client, ctx.Args[1:]) {
+			fmt.Printf("Usage: %stranscribe <file> [--context | --send]\n", ctx.Config.CommandPrefix)
+		}
+	})
+	commands.Register("voice", []string{}, "Toggle voice mode, spoken prompts and responses", func(ctx *commands.Context) {
+		toggleVoiceMode(*ctx.Config)
+	})
+	commands.Register("set", []string{"stop", "seed"}, "Change a request parameter for this session, without saving it", func(ctx *commands.Context) {
+		if !setParameter(ctx.Config, ctx.Rest) {
+			fmt.Printf("Usage: %sset [stop [\"<sequence>\" ...] | seed [<n>]]\n", ctx.Config.CommandPrefix)
+		}
+	})
+	commands.Register("alternatives", []string{"n"}, "Generate n responses to \"<prompt>\" and pick the one to keep", func(ctx *commands.Context) {
+		usage := fmt.Sprintf("Usage: %salternatives <2-%d> \"<prompt>\"\n", ctx.Config.CommandPrefix, MAX_ALTERNATIVES)
+		if len(ctx.Args) < 3 {
+			fmt.Print(usage)
+			return
+		}
+		n, err := strconv.Atoi(ctx.Args[1])
+		if err != nil || n < 2 || n > MAX_ALTERNATIVES {
+			fmt.Print(usage)
+			return
+		}
+		prompt := strings.Trim(strings.TrimSpace(strings.TrimPrefix(ctx.Rest, ctx.Args[1])), "\"")
+		prompt, ok := filterPrompt(prompt)
+		if !ok || !checkBudget(*ctx.Config) || !moderate(ctx.Client, *ctx.Config, MODERATE_PROMPTS, prompt) {
+			return
+		}
+		recallMemories(ctx.Client, *ctx.Config, prompt)
+		response, ok := runAlternatives(ctx.Client, *ctx.Config, n, prompt)
+		if !ok {
+			return
+		}
+		chatResponse.Reset()
+		chatResponse.WriteString(response)
+		autoCopy(*ctx.Config, response)
+		lastExchange = &Exchange{
+			Prompt:       prompt,
+			Response:     response,
+			Model:        ctx.Config.Model,
+			SystemPrompt: ctx.Config.SystemPrompt,
+		}
+		maybeGenerateTitle(ctx.Client, *ctx.Config, prompt, response)
+		compactHistory(ctx.Client, *ctx.Config)
+		archiveSession(*ctx.Config)
+	})
+	commands.Register("summarize", []string{"url", "file"}, "Print a structured summary, kept out of the conversation", func(ctx *commands.Context) {
+		if len(ctx.Args) != 2 {
+			fmt.Printf("Usage: %ssummarize <url | file>\n", ctx.Config.CommandPrefix)
+			return
+		}
+		summarizeCommand(ctx.Client, *ctx.Config, ctx.Args[1])
+	})
+	commands.Register("translate", []string{"lang"}, "Translate <text | file | clipboard>, to TranslateTo or the locale language by default", func(ctx *commands.Context) {
+		if !translateCommand(ctx.Client, *ctx.Config, ctx.Rest) {
+			fmt.Printf("Usage: %stranslate [lang] <text | file | clipboard>\n", ctx.Config.CommandPrefix)
+		}
+	})
+	commands.Register("explain", []string{"file"}, "Explain a file or a selection such as file:10-40, kept out of the conversation", func(ctx *commands.Context) {
+		if len(ctx.Args) != 2 {
+			fmt.Printf("Usage: %sexplain <file>\n", ctx.Config.CommandPrefix)
+			return
+		}
+		explainCommand(ctx.Client, *ctx.Config, ctx.Args[1])
+	})
+	commands.Register("commit", []string{}, "Stage changes, generate a commit message, edit it and commit", func(ctx *commands.Context) {
+		commitCommand(ctx.Client, *ctx.Config)
+	})
+	commands.Register("pr", []string{"base"}, "Generate a pull request title and description for the current branch", func(ctx *commands.Context) {
+		if len(ctx.Args) > 2 {
+			fmt.Printf("Usage: %spr [base]\n", ctx.Config.CommandPrefix)
+			return
+		}
+		base := ""
+		if len(ctx.Args) == 2 {
+			base = ctx.Args[1]
+		}
+		prCommand(ctx.Client, *ctx.Config, base)
+	})
+	commands.Register("gentest", []string{"file"}, "Generate table-driven tests for the exported functions of a Go file", func(ctx *commands.Context) {
+		if len(ctx.Args) != 2 {
+			fmt.Printf("Usage: %sgentest <file.go>\n", ctx.Config.CommandPrefix)
+			return
+		}
+		gentestCommand(ctx.Client, *ctx.Config, ctx.Args[1])
+	})
+	commands.Register("fix", []string{"command"}, "Run FixCommand, or the given command, and fix its failures until it succeeds", func(ctx *commands.Context) {
+		fixCommand(ctx.Client, *ctx.Config, ctx.Rest)
+	})
+	commands.Register("sh", []string{}, "Turn \"<what you want>\" into a shell command and run it once confirmed", func(ctx *commands.Context) {
+		request := ctx.Rest
+		if request == "" {
+			fmt.Printf("Usage: %ssh \"<what you want>\"\n", ctx.Config.CommandPrefix)
+			return
+		}
+		shCommand(ctx.Client, *ctx.Config, request)
+	})
+	commands.Register("capture-pane", []string{"target", "lines"}, "Add the last lines of the terminal (tmux pane, iTerm2, WezTerm or kitty) to the context", func(ctx *commands.Context) {
+		if !capturePaneCommand(ctx.Args[1:]) {
+			fmt.Printf("Usage: %scapture-pane [target] [lines]\n", ctx.Config.CommandPrefix)
+		}
+	})
+	commands.Register("watch-clipboard", []string{}, "Toggle clipboard watch mode, new clipboard content is offered as context", func(ctx *commands.Context) {
+		toggleClipboardWatch()
+	})
+	commands.Register("paste", []string{"message"}, "Insert the clipboard in the next message, fenced if it looks like code (or press Ctrl-V)", func(ctx *commands.Context) {
+		pasteCommand(ctx.Args[1:])
+	})
+	commands.Register("queue", []string{"flush", "clear"}, "List the messages queued while offline, send them or drop them", func(ctx *commands.Context) {
+		if len(ctx.Args) == 1 {
+			printQueue()
+			return
+		}
+		switch ctx.Args[1] {
+		case "flush":
+			flushQueue()
+		case "clear":
+			saveQueue(nil)
+			fmt.Println("Queue cleared")
+		default:
+			fmt.Printf("Usage: %squeue [flush | clear]\n", ctx.Config.CommandPrefix)
+		}
+	})
+	commands.Register("health", []string{}, "Check the API key, the configured models and the latency", func(ctx *commands.Context) {
+		checkHealth(ctx.Client, *ctx.Config)
+	})
+	commands.Register("preview", []string{"path"}, "Preview an image in the terminal, or open it", func(ctx *commands.Context) {
+		if len(ctx.Args) != 2 {
+			fmt.Printf("Usage: %spreview <path>\n", ctx.Config.CommandPrefix)
+			return
+		}
+		previewImage(*ctx.Config, ctx.Args[1])
+	})
+	commands.Register("copy", append([]string{"all", "user", "assistant"}, copyFormats()...), "Copy the last LLM response to clipboard, or the conversation, as Markdown, plain text or HTML", func(ctx *commands.Context) {
+		copyCommand(*ctx.Config, ctx.Args[1:])
+	})
+	commands.Register("tee", []string{"off"}, "Append what is shown to <path>, Markdown for .md files, or stop with `off`", func(ctx *commands.Context) {
+		teeCommand(ctx.Args[1:])
+	})
+	commands.Register("config", []string{"edit", "path", "reset", "diff", "--persist"}, "Show / edit the config, for the session unless --persist is given", func(ctx *commands.Context) {
+		// Changes only apply to the session unless they are persisted
+		persist := slices.Contains(ctx.Args, "--persist")
+		args := slices.DeleteFunc(slices.Clone(ctx.Args), func(arg string) bool { return arg == "--persist" })
+		if len(args) == 1 && !persist {
+			printConfig(*ctx.Config)
+			printConfigOverrides(*ctx.Config)
+			return
+		}
+		switch {
+		case len(args) == 2 && args[1] == "edit" && !persist:
+			editConfig(ctx.Config)
+			return
+		case len(args) == 2 && args[1] == "path" && !persist:
+			printConfigPath()
+			return
+		case len(args) == 2 && args[1] == "diff" && !persist:
+			printConfigDiff(*ctx.Config)
+			return
+		case len(args) >= 2 && len(args) <= 3 && args[1] == "reset":
+			resetConfig(ctx.Config, strings.Join(args[2:], ""), persist)
+			return
+		}
+		if len(args) < 3 {
+			fmt.Printf("Usage: %sconfig [edit | path | diff | reset [Field] [--persist] | <Field> <Value>... [--persist]]\n", ctx.Config.CommandPrefix)
+			return
+		}
+		// Values can be quoted, lists take several of them
+		values, err := splitArgs(ctx.Rest)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		values = slices.DeleteFunc(values, func(arg string) bool { return arg == "--persist" })
+		name := values[0]
+		field, ok := reflect.TypeOf(*ctx.Config).FieldByNameFunc(func(fieldName string) bool { return strings.EqualFold(fieldName, name) })
+		if !ok {
+			if suggestion, ok := commands.Closest(name, configFields()); ok {
+				fmt.Print(i18n.T("Unknown config field: %s, did you mean `%s`?\n", name, suggestion))
+			} else {
+				fmt.Print(i18n.T("Unknown config field: %s\n", name))
+			}
+			return
+		}
+		if !editableType(field.Type) {
+			fmt.Printf("Error: %s can only be edited in `%s`\n", field.Name, configFile)
+			return
+		}
+		value, err := parseFieldValue(field, values[1:])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		reflect.ValueOf(ctx.Config).Elem().FieldByIndex(field.Index).Set(value.Convert(field.Type))
+		shown := formatFieldValue(reflect.ValueOf(maskSecrets(*ctx.Config)).FieldByIndex(field.Index))
+		if persist {
+			persistConfigFields(*ctx.Config, field.Name)
+			fmt.Print(i18n.T("Config saved: %s = %s\n", field.Name, shown))
+		} else {
+			fmt.Print(i18n.T("Config updated for this session: %s = %s, use `--persist` to save it\n", field.Name, shown))
+		}
+	})
+	commands.Register("budget", []string{"override"}, "Show spending against the budgets, or lift the hard stop", func(ctx *commands.Context) {
+		if len(ctx.Args) == 1 {
+			printBudget(*ctx.Config)
+			return
+		}
+		if len(ctx.Args) != 2 || ctx.Args[1] != "override" {
+			fmt.Printf("Usage: %sbudget [override]\n", ctx.Config.CommandPrefix)
+			return
+		}
+		budgetOverride = true
+		fmt.Println("Budget limits overridden for this session")
+	})
+	commands.Register("pricing", []string{"model"}, "Show the pricing table, or the price of <model>", func(ctx *commands.Context) {
+		if len(ctx.Args) > 2 {
+			fmt.Printf("Usage: %spricing [model]\n", ctx.Config.CommandPrefix)
+			return
+		}
+		model := ""
+		if len(ctx.Args) == 2 {
+			model = ctx.Args[1]
+		}
+		printPricing(*ctx.Config, model)
+	})
+	commands.Register("remember", []string{"fact"}, "Store a fact that is added to every system prompt", func(ctx *commands.Context) {
+		fact := ctx.Rest
+		if fact == "" {
+			fmt.Printf("Error: `%sremember <fact>` command expects a fact\n", ctx.Config.CommandPrefix)
+			return
+		}
+		remember(fact)
+	})
+	commands.Register("memory", []string{"list", "forget"}, "List the stored facts, or forget one by number (or `all`)", func(ctx *commands.Context) {
+		if len(ctx.Args) == 1 || (len(ctx.Args) == 2 && ctx.Args[1] == "list") {
+			listMemories()
+			return
+		}
+		if len(ctx.Args) != 3 || ctx.Args[1] != "forget" {
+			fmt.Printf("Usage: %smemory [list | forget <n | all>]\n", ctx.Config.CommandPrefix)
+			return
+		}
+		forgetMemory(ctx.Args[2])
+	})
+	commands.Register("title", []string{"name"}, "Show the session title, or rename the session", func(ctx *commands.Context) {
+		setTitle(*ctx.Config, ctx.Rest)
+	})
+	commands.Register("sessions", []string{}, "List the archived sessions", func(ctx *commands.Context) {
+		archiveSession(*ctx.Config)
+		printSessions()
+	})
+	commands.Register("continue-last", []string{}, "Resume the most recently active session", func(ctx *commands.Context) {
+		archiveSession(*ctx.Config)
+		continueLastSession(ctx.Config)
+	})
+	commands.Register("recall", []string{"query", "load", "quote"}, "Search archived conversations, then load or quote a result", func(ctx *commands.Context) {
+		if len(ctx.Args) == 3 && (ctx.Args[1] == "load" || ctx.Args[1] == "quote") {
+			result, ok := recallResultAt(ctx.Args[2])
+			if !ok {
+				return
+			}
+			if ctx.Args[1] == "load" {
+				archiveSession(*ctx.Config)
+				resumeSession(result.Session, ctx.Config)
+				fmt.Printf("Loaded conversation `%s` (%d messages)\n", result.Session.DisplayName(), len(history.Messages))
+			} else {
+				addContextItem(session.ContextItem{Name: result.Session.DisplayName(), Kind: session.CONTEXT_EXCERPT, Content: result.Chunk.Text})
+				fmt.Printf("Added excerpt from `%s` to system prompt\n", result.Session.DisplayName())
+			}
+			return
+		}
+		query := ctx.Rest
+		if query == "" {
+			fmt.Printf("Usage: %srecall \"<query>\" | load <n> | quote <n>\n", ctx.Config.CommandPrefix)
+			return
+		}
+		recallConversations(ctx.Client, *ctx.Config, query)
+	})
+	commands.Register("rate", []string{"1-5", "up", "down"}, "Rate the last response, with an optional comment", func(ctx *commands.Context) {
+		if len(ctx.Args) < 2 {
+			fmt.Printf("Usage: %srate <1-5 | up | down> [comment]\n", ctx.Config.CommandPrefix)
+			return
+		}
+		rating, ok := parseRating(ctx.Args[1])
+		if !ok {
+			fmt.Printf("Error: `%s` is not a valid rating, expected 1-5, `up` or `down`\n", ctx.Args[1])
+			return
+		}
+		rateLastResponse(rating, strings.Join(ctx.Args[2:], " "))
+	})
+	commands.Register("stats", []string{"csv"}, "Show session statistics, or export them as CSV to <path>", func(ctx *commands.Context) {
+		if len(ctx.Args) == 1 {
+			printSessionStats()
+			return
+		}
+		if len(ctx.Args) != 3 || ctx.Args[1] != "csv" {
+			fmt.Printf("Usage: %sstats [csv <path>]\n", ctx.Config.CommandPrefix)
+			return
+		}
+		exportSessionStats(ctx.Args[2])
+	})
+	commands.Register("help", commands.Groups[:len(commands.Groups)-1], "Display this help, or the help of a group or a command", func(ctx *commands.Context) {
+		commands.PrintHelp(ctx.Config.CommandPrefix, ctx.Rest)
+	})
+	commands.Register("exit", []string{}, "Exit the REPL", func(ctx *commands.Context) {
+		ctx.Exit = true
+		fmt.Println(i18n.T("Goodbye!"))
+	})
+}
+
+func saveOrLoadCommand(ctx *commands.Context) {
+	// TODO: autocomplete file path
+	// TODO: underline file names
+	action := ctx.Args[0]
+	if len(ctx.Args) > 2 {
+		fmt.Printf("Error: `%s%s <path>` command expects only a file path\n", ctx.Config.CommandPrefix, action)
+		return
+	}
+
+	path := ctx.Config.DefaultHistoryPath
+	if path == "" {
+		path = slugify(currentSession.DisplayName()) + ".json"
+	}
+	if len(ctx.Args) == 2 {
+		path = ctx.Args[1]
+	}
+
+	if action == "save" {
+		saveHistory(path)
+	} else {
+		loadHistory(path)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import "gpt/commands"
+
+// Groups, argument forms and examples of `/help <command>`
+func init() {
+	describe := func(group string, name string, usage []string, examples ...string) {
+		commands.Describe(name, commands.Details{Group: group, Usage: usage, Examples: examples})
+	}
+
+	describe(commands.GROUP_SESSION, "save", []string{"[path]"}, "save notes.json")
+	describe(commands.GROUP_SESSION, "load", []string{"[path]"}, "load notes.json")
+	describe(commands.GROUP_SESSION, "history", []string{"[n]"}, "history 20")
+	describe(commands.GROUP_SESSION, "grep", []string{"<regex>"}, "grep (?i)timeout")
+	describe(commands.GROUP_SESSION, "title", []string{"", "<name>"}, "title Release planning")
+	describe(commands.GROUP_SESSION, "sessions", nil)
+	describe(commands.GROUP_SESSION, "continue-last", nil)
+	describe(commands.GROUP_SESSION, "recall", []string{"\"<query>\"", "load <n>", "quote <n>"}, "recall \"the retry bug in the uploader\"", "recall load 2")
+	describe(commands.GROUP_SESSION, "export", []string{"bundle [path]"}, "export bundle review.zip")
+	describe(commands.GROUP_SESSION, "import", []string{"bundle <path>"}, "import bundle review.zip")
+	describe(commands.GROUP_SESSION, "queue", []string{"", "flush", "clear"})
+	describe(commands.GROUP_SESSION, "rate", []string{"<1-5 | up | down> [comment]"}, "rate 4", "rate down made up an API")
+	describe(commands.GROUP_SESSION, "stats", []string{"", "csv <path>"}, "stats csv stats.csv")
+
+	describe(commands.GROUP_CONTEXT, "system", []string{"show", "reset"})
+	describe(commands.GROUP_CONTEXT, "embed", []string{"<file | directory | - | clipboard> ...", "list", "watch <file> ...", "unwatch <file> ...", "remove <name> ...", "clear"},
+		"embed main.go", "embed main.go:120-200", "embed main.go#loadConfig", "embed watch main.go")
+	describe(commands.GROUP_CONTEXT, "transcribe", []string{"<file> [--context | --send]"}, "transcribe meeting.mp3 --context")
+	describe(commands.GROUP_CONTEXT, "capture-pane", []string{"[target] [lines]"}, "capture-pane", "capture-pane %1 200")
+	describe(commands.GROUP_CONTEXT, "watch-clipboard", nil)
+	describe(commands.GROUP_CONTEXT, "paste", []string{"[message]"}, "paste why does this fail?")
+	describe(commands.GROUP_CONTEXT, "remember", []string{"<fact>"}, "remember I deploy with Docker Compose")
+	describe(commands.GROUP_CONTEXT, "memory", []string{"[list]", "forget <n | all>"}, "memory forget 2")
+
+	describe(commands.GROUP_OUTPUT, "last", []string{"[n]"}, "last 2")
+	describe(commands.GROUP_OUTPUT, "render", []string{"<n>"}, "render 3")
+	describe(commands.GROUP_OUTPUT, "copy", []string{"[markdown | plain | html]", "<all | user | assistant> [markdown | plain | html]"}, "copy all", "copy plain", "copy assistant html")
+	describe(commands.GROUP_OUTPUT, "preview", []string{"<path>"}, "preview images/cat.png")
+	describe(commands.GROUP_OUTPUT, "voice", nil)
+	describe(commands.GROUP_OUTPUT, "tee", []string{"", "<path>", "off"}, "tee notes.md", "tee off")
+
+	describe(commands.GROUP_SETTINGS, "config", []string{"", "edit", "path", "diff", "reset [Field] [--persist]", "<Field> <Value> [--persist]"}, "config Model gpt-4o-mini", "config Theme light --persist", "config reset Theme")
+	describe(commands.GROUP_SETTINGS, "set", []string{"stop [\"<sequence>\" ...]", "seed [<n>]"}, "set stop \"\\n\\n\"", "set seed 42")
+	describe(commands.GROUP_SETTINGS, "budget", []string{"", "override"})
+	describe(commands.GROUP_SETTINGS, "pricing", []string{"[model]"}, "pricing gpt-4o")
+	describe(commands.GROUP_SETTINGS, "health", nil)
+
+	describe(commands.GROUP_TOOLS, "compare", []string{"\"<prompt>\""}, "compare \"Explain CRDTs in one paragraph\"")
+	describe(commands.GROUP_TOOLS, "alternatives", []string{"<2-9> \"<prompt>\""}, "alternatives 3 \"Name this library\"")
+	describe(commands.GROUP_TOOLS, "imagine", []string{"\"<prompt>\""}, "imagine \"a lighthouse in a storm, watercolor\"")
+	describe(commands.GROUP_TOOLS, "imgedit", []string{"<path> [--mask <mask>] [\"<instructions>\"]"}, "imgedit photo.png --mask sky.png \"make it sunset\"", "imgedit logo.png")
+	describe(commands.GROUP_TOOLS, "summarize", []string{"<url | file>"}, "summarize https://go.dev/blog/loopvar-preview")
+	describe(commands.GROUP_TOOLS, "translate", []string{"[lang] <text | file | clipboard>"}, "translate French README.md", "translate clipboard")
+	describe(commands.GROUP_TOOLS, "explain", []string{"<file | file:start-end | file.go#Func>"}, "explain main.go:10-40")
+	describe(commands.GROUP_TOOLS, "commit", nil)
+	describe(commands.GROUP_TOOLS, "pr", []string{"[base]"}, "pr main")
+	describe(commands.GROUP_TOOLS, "gentest", []string{"<file.go>"}, "gentest calculator.go")
+	describe(commands.GROUP_TOOLS, "fix", []string{"[command]"}, "fix go test ./...")
+	describe(commands.GROUP_TOOLS, "sh", []string{"\"<what you want>\""}, "sh \"find the 10 biggest files here\"")
+
+	describe(commands.GROUP_OTHER, "help", []string{"", "<group>", "<command>"}, "help context", "help embed")
+	describe(commands.GROUP_OTHER, "exit", nil)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/provider"
+)
+
+const DEFAULT_REVIEW_FILE = "review.md"
+
+const REVIEW_PROMPT = "You are a senior engineer reviewing code. Review the following file, or diff of a file, against this rubric: " +
+	"bugs (logic errors, edge cases, error handling, concurrency), security (injection, secrets, unsafe input handling, permissions) and style (readability, naming, duplication). " +
+	"Answer in Markdown with the `### Bugs`, `### Security` and `### Style` headings. Under each, list findings as bullets with a severity (high, medium or low), " +
+	"the location and a suggested fix, or write `No issues found.` Only report real problems."
+
+const COMBINE_REVIEW_PROMPT = "The following are reviews of consecutive parts of the same file. Merge them into a single review " +
+	"with the `### Bugs`, `### Security` and `### Style` headings, removing duplicates. Answer with the review only."
+
+type reviewItem struct {
+	Path  string
+	Input string
+}
+
+type reviewResult struct {
+	Path   string
+	Review string
+	Err    error
+}
+
+// Implements `go-gpt review [--output FILE] [--no-cache] <path | ref-range>`
+func runReviewCommand(args []string) {
+	fs := flag.NewFlagSet("review", flag.ExitOnError)
+	output := fs.String("output", DEFAULT_REVIEW_FILE, "write the report to this Markdown file")
+	noCache := fs.Bool("no-cache", false, "don't use the response cache, even if Cache is set")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Printf("Usage: %s review [--output FILE] [--no-cache] <path | ref-range>\n", os.Args[0])
+		os.Exit(1)
+	}
+	target := fs.Arg(0)
+
+	client, config, shutdown := setupServer(*noCache)
+	defer shutdown()
+
+	var items []reviewItem
+	var err error
+	if _, statErr := os.Stat(target); statErr == nil {
+		items, err = reviewFiles(target, maxEmbedSize(&config))
+	} else {
+		items, err = reviewDiffs(target)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(items) == 0 {
+		fmt.Println("Nothing to review")
+		return
+	}
+	if !checkBudget(config) {
+		os.Exit(1)
+	}
+
+	results := reviewAll(client, config.Model, provider.Concurrency(config), items)
+	report := reviewReport(target, results)
+	if err := os.WriteFile(*output, []byte(report), 0644); err != nil {
+		fmt.Printf("Error writing `%s`: %v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Reviewed %d files, the report is in `%s`\n", len(results), *output)
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	notifyWebhooks(config, EVENT_REVIEW, map[string]any{
+		"target": target,
+		"files":  len(results),
+		"failed": failed,
+		"output": *output,
+		"report": report,
+	})
+}
+
+// The files of a path, the whole file is reviewed
+func reviewFiles(path string, maxSize int64) ([]reviewItem, error) {
+	files := []string{path}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		var err error
+		if files, err = gitFiles(path); err != nil {
+			files, err = walkFiles(path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	items := []reviewItem{}
+	for _, file := range files {
+		if skip, _ := shouldSkipFile(file, maxSize); skip {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, reviewItem{Path: filepath.ToSlash(file), Input: string(content)})
+	}
+	return items, nil
+}
+
+// The files changed in a ref range, e.g. `main..HEAD`, only the diff is reviewed
+func reviewDiffs(refRange string) ([]reviewItem, error) {
+	names, err := git("diff", "--name-only", "--diff-filter=d", refRange)
+	if err != nil {
+		return nil, err
+	}
+	items := []reviewItem{}
+	for _, name := range strings.Split(strings.TrimSpace(names), "\n") {
+		if name == "" {
+			continue
+		}
+		diff, err := git("diff", refRange, "--", name)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, reviewItem{Path: name, Input: diff})
+	}
+	return items, nil
+}
+
+// Reviews `workers` files at a time, the results keep the order of the items
+func reviewAll(client *openai.Client, model string, workers int, items []reviewItem) []reviewResult {
+	results := make([]reviewResult, len(items))
+	provider.RunPool(workers, len(items), func(i int) {
+		review, err := reviewFile(client, model, items[i])
+		results[i] = reviewResult{Path: items[i].Path, Review: review, Err: err}
+		fmt.Printf("Reviewed `%s`\n", items[i].Path)
+	})
+	return results
+}
+
+// Big files are reviewed chunk by chunk, then the reviews are merged
+func reviewFile(client *openai.Client, model string, item reviewItem) (string, error) {
+	reviews := []string{}
+	for _, chunk := range splitChunks(item.Input, SUMMARY_CHUNK_SIZE) {
+		review, err := chat.Complete(client, model, REVIEW_PROMPT, fmt.Sprintf("File `%s`:\n\n%s", item.Path, chunk))
+		if err != nil {
+			return "", err
+		}
+		reviews = append(reviews, review)
+	}
+	if len(reviews) == 1 {
+		return reviews[0], nil
+	}
+	return chat.Complete(client, model, COMBINE_REVIEW_PROMPT, strings.Join(reviews, "\n\n---\n\n"))
+}
+
+func reviewReport(target string, results []reviewResult) string {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+	sb := strings.Builder{}
+	sb.WriteString("# Code review\n\n")
+	sb.WriteString(fmt.Sprintf("Review of `%s` on %s, %d files.\n\n", target, time.Now().Format("2006-01-02 15:04"), len(results)))
+	for _, result := range results {
+		sb.WriteString(fmt.Sprintf("- `%s`\n", result.Path))
+	}
+	for _, result := range results {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", result.Path))
+		if result.Err != nil {
+			sb.WriteString(fmt.Sprintf("Error reviewing this file: %v\n", result.Err))
+			continue
+		}
+		sb.WriteString(strings.TrimSpace(result.Review) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+)
+
+const EVENT_SCHEDULE = "schedule"
+
+// Functions of the schedule templates, e.g. `{{tail "app.log" 200}}`
+var scheduleFuncs = template.FuncMap{
+	"file": func(path string) (string, error) {
+		data, err := os.ReadFile(path)
+		return string(data), err
+	},
+	"tail": func(path string, n int) (string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		return strings.Join(lines[max(0, len(lines)-n):], "\n"), nil
+	},
+	"date": func(layout string) string {
+		return time.Now().Format(layout)
+	},
+}
+
+func renderTemplate(name string, text string, data any) (string, error) {
+	tmpl, err := template.New(name).Funcs(scheduleFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// Implements `go-gpt schedule [--run NAME] [--no-cache]`
+func runScheduleCommand(args []string) {
+	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
+	run := fs.String("run", "", "run the schedule with this name once and exit")
+	noCache := fs.Bool("no-cache", false, "don't use the response cache, even if Cache is set")
+	fs.Parse(args)
+
+	client, config, shutdown := setupServer(*noCache)
+	defer shutdown()
+	if len(config.Schedules) == 0 {
+		fmt.Printf("Error: no schedules, add them to `%s`\n", configFile)
+		os.Exit(1)
+	}
+
+	if *run != "" {
+		for _, schedule := range config.Schedules {
+			if schedule.Name == *run {
+				runSchedule(client, config, schedule)
+				return
+			}
+		}
+		fmt.Printf("Error: no schedule named `%s`\n", *run)
+		os.Exit(1)
+	}
+
+	scheduler := cron.New()
+	for _, schedule := range config.Schedules {
+		if _, err := scheduler.AddFunc(schedule.Cron, func() { runSchedule(client, config, schedule) }); err != nil {
+			fmt.Printf("Error: invalid cron expression `%s` for `%s`: %v\n", schedule.Cron, schedule.Name, err)
+			os.Exit(1)
+		}
+	}
+	scheduler.Start()
+	fmt.Printf("Running %d schedules, press Ctrl-C to stop\n", len(config.Schedules))
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	<-interrupt
+	// Waits for the running prompts
+	<-scheduler.Stop().Done()
+}
+
+func runSchedule(client *openai.Client, config config.Config, schedule config.Schedule) {
+	logf := func(format string, args ...any) {
+		fmt.Printf("[%s] %s: %s\n", time.Now().Format("2006-01-02 15:04:05"), schedule.Name, fmt.Sprintf(format, args...))
+	}
+	if !checkBudget(config) {
+		return
+	}
+	prompt, err := renderTemplate(schedule.Name, schedule.Prompt, nil)
+	if err != nil {
+		logf("Error rendering the prompt: %v", err)
+		return
+	}
+	model := config.Model
+	if schedule.Model != "" {
+		model = schedule.Model
+	}
+	result, err := chat.Complete(client, model, config.SystemPrompt, prompt)
+	if err != nil {
+		logf("Error: %v", err)
+		return
+	}
+
+	output := ""
+	if schedule.Output != "" {
+		if output, err = renderTemplate(schedule.Name, schedule.Output, nil); err != nil {
+			logf("Error rendering the output path: %v", err)
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			logf("Error: %v", err)
+			return
+		}
+		if err := os.WriteFile(output, []byte(result+"\n"), 0644); err != nil {
+			logf("Error writing `%s`: %v", output, err)
+			return
+		}
+		logf("written to `%s`", output)
+	} else {
+		logf("done")
+	}
+	notifyWebhooks(config, EVENT_SCHEDULE, map[string]any{"name": schedule.Name, "output": output, "result": result})
+}
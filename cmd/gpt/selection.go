@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gpt/session"
+)
+
+// Handles `/embed file:120-200` and `/embed file.go#Symbol`. Returns false if
+// the argument is not a selection.
+func embedSelection(arg string) bool {
+	path, content, ok, err := readSelection(arg)
+	if !ok {
+		return false
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return true
+	}
+	addContextItem(session.ContextItem{Name: arg, Kind: session.CONTEXT_FILE, Path: path, Content: content})
+	fmt.Printf("Added `%s` to system prompt\n", arg)
+	return true
+}
+
+// Returns the file and content of a selection, ok is false if the argument
+// is not a selection
+func readSelection(arg string) (path string, content string, ok bool, err error) {
+	if path, symbol, found := strings.Cut(arg, "#"); found && fileExists(path) {
+		content, err := extractGoSymbol(path, symbol)
+		return path, content, true, err
+	}
+
+	idx := strings.LastIndexByte(arg, ':')
+	if idx == -1 || !fileExists(arg[:idx]) {
+		return "", "", false, nil
+	}
+	path = arg[:idx]
+	start, end, err := parseLineRange(arg[idx+1:])
+	if err != nil {
+		return path, "", true, err
+	}
+	content, err = extractLines(path, start, end)
+	return path, content, true, err
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// Accepts `N`, `N-M`, `N-` (until the end) and `-M` (from the start)
+func parseLineRange(spec string) (int, int, error) {
+	from, to, isRange := strings.Cut(spec, "-")
+	if !isRange {
+		to = from
+	}
+	start, end := 1, 0
+	var err error
+	if from != "" {
+		if start, err = strconv.Atoi(from); err != nil || start < 1 {
+			return 0, 0, fmt.Errorf("invalid line range `%s`", spec)
+		}
+	}
+	if to != "" {
+		if end, err = strconv.Atoi(to); err != nil || end < start {
+			return 0, 0, fmt.Errorf("invalid line range `%s`", spec)
+		}
+	}
+	return start, end, nil
+}
+
+// Lines are numbered from 1, an end of 0 means the end of the file
+func extractLines(path string, start int, end int) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("can't read file `%s`", path)
+	}
+	lines := strings.Split(string(data), "\n")
+	if start > len(lines) {
+		return "", fmt.Errorf("`%s` only has %d lines", path, len(lines))
+	}
+	if end == 0 || end > len(lines) {
+		end = len(lines)
+	}
+	return strings.Join(lines[start-1:end], "\n"), nil
+}
+
+// Finds a function, method (`Type.Method`), type, variable or constant
+// declaration, along with its doc comment
+func extractGoSymbol(path string, symbol string) (string, error) {
+	if filepath.Ext(path) != ".go" {
+		return "", fmt.Errorf("symbol selection only works for Go files")
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("can't read file `%s`", path)
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("can't parse `%s`: %v", path, err)
+	}
+
+	slice := func(node ast.Node, doc *ast.CommentGroup) string {
+		start := node.Pos()
+		if doc != nil {
+			start = doc.Pos()
+		}
+		return string(src[fset.Position(start).Offset:fset.Position(node.End()).Offset])
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if goFuncName(d) == symbol || d.Name.Name == symbol {
+				return slice(d, d.Doc), nil
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if !specDeclares(spec, symbol) {
+					continue
+				}
+				// Take the whole declaration unless it is a group
+				if len(d.Specs) == 1 {
+					return slice(d, d.Doc), nil
+				}
+				return slice(spec, specDoc(spec)), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("`%s` is not declared in `%s`", symbol, path)
+}
+
+func goFuncName(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return d.Name.Name
+	}
+	recv := d.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	// Generic receivers, e.g. `List[T]`
+	if index, ok := recv.(*ast.IndexExpr); ok {
+		recv = index.X
+	}
+	if index, ok := recv.(*ast.IndexListExpr); ok {
+		recv = index.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + d.Name.Name
+	}
+	return d.Name.Name
+}
+
+func specDeclares(spec ast.Spec, symbol string) bool {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return s.Name.Name == symbol
+	case *ast.ValueSpec:
+		for _, name := range s.Names {
+			if name.Name == symbol {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func specDoc(spec ast.Spec) *ast.CommentGroup {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return s.Doc
+	case *ast.ValueSpec:
+		return s.Doc
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/provider"
+	"gpt/session"
+)
+
+const DEFAULT_LISTEN_ADDRESS = "localhost:8080"
+
+// Implements `go-gpt serve [--listen ADDRESS] [--no-cache]`, and the bot
+// bridges such as `go-gpt serve slack`
+func runServeCommand(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "slack":
+			runSlackBridge(args[1:])
+			return
+		case "discord":
+			runDiscordBridge(args[1:])
+			return
+		case "telegram":
+			runTelegramBridge(args[1:])
+			return
+		}
+	}
+
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", DEFAULT_LISTEN_ADDRESS, "address to listen on")
+	noCache := fs.Bool("no-cache", false, "don't use the response cache, even if Cache is set")
+	fs.Parse(args)
+
+	client, config, shutdown := setupServer(*noCache)
+	defer shutdown()
+	token := config.ServerToken
+	if env := os.Getenv("GPT_SERVER_TOKEN"); env != "" {
+		token = env
+	}
+	if token == "" {
+		fmt.Println("Error: set `ServerToken` in the config, or GPT_SERVER_TOKEN in .env, clients send it as `Authorization: Bearer <token>`")
+		os.Exit(1)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", authorize(token, promhttp.Handler()))
+	mux.Handle("/", authorize(token, apiHandler(client, config)))
+	registerWebUI(mux)
+
+	fmt.Printf("Listening on %s, open http://%s/#token=<token> for the web UI\n", *listen, *listen)
+	if err := http.ListenAndServe(*listen, mux); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// Sets up everything the REPL does before chatting. The returned function
+// flushes the pending spans.
+func setupServer(noCache bool) (*openai.Client, config.Config, func()) {
+	if err := godotenv.Load(); err != nil {
+		fmt.Println("Error loading .env file")
+		os.Exit(1)
+	}
+	config := loadConfig()
+	if err := session.SetupEncryption(config); err != nil {
+		log.Fatalf("Fatal error: can't set up encryption: %v", err)
+	}
+	provider.LoadPricing(config.Pricing)
+	// Anyone who can reach the server or the bot would run them
+	if config.ServerTools {
+		enableCodeExecution(config)
+		enableFileTools(config)
+		enableHTTPTool(config)
+	}
+	if err := loadFilters(config.Filters); err != nil {
+		log.Fatalf("Fatal error: can't load filters: %v", err)
+	}
+	loadMemories()
+	client := provider.NewClient(config, os.Getenv("OPENAI_API_KEY"))
+	chat.CacheResponses = config.Cache && !noCache
+	chat.Timeout = time.Duration(config.Timeout)
+	return client, config, chat.SetupTracing(config)
+}
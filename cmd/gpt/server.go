@@ -0,0 +1,282 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/session"
+	"gpt/tools"
+)
+
+// The chat engine works on the current session, so the server handles one
+// conversation turn at a time
+var serverMutex sync.Mutex
+
+type sessionSummary struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Updated  time.Time `json:"updated"`
+	Messages int       `json:"messages"`
+}
+
+type newSessionRequest struct {
+	SystemPrompt string `json:"system_prompt"`
+	Model        string `json:"model"`
+}
+
+type messageRequest struct {
+	Content string `json:"content"`
+	Stream  bool   `json:"stream"`
+}
+
+type messageResponse struct {
+	Content          string `json:"content"`
+	Model            string `json:"model"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// Requests need the bearer token, and browsers may only send them from the
+// web UI: cross-origin requests are refused
+func authorize(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+				writeError(w, http.StatusForbidden, "cross-origin requests are not allowed")
+				return
+			}
+		}
+		given, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// JSON bodies must say so, which browsers can't do in cross-site requests
+// without a preflight
+func isJSON(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// Returns the session ID of the path, or reports a 404 when it can't name a
+// session file
+func sessionID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if !session.ValidID(id) {
+		writeError(w, http.StatusNotFound, "no such session")
+		return "", false
+	}
+	return id, true
+}
+
+// Routes of the REST API
+func apiHandler(client *openai.Client, config config.Config) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sessions", func(w http.ResponseWriter, r *http.Request) {
+		sessions, err := session.List()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		summaries := []sessionSummary{}
+		for _, sess := range sessions {
+			summaries = append(summaries, sessionSummary{ID: sess.ID, Title: sess.Title, Updated: sess.Updated, Messages: len(sess.Messages)})
+		}
+		writeJSON(w, http.StatusOK, summaries)
+	})
+	mux.HandleFunc("POST /sessions", func(w http.ResponseWriter, r *http.Request) {
+		var req newSessionRequest
+		if r.ContentLength != 0 {
+			if !isJSON(r) {
+				writeError(w, http.StatusUnsupportedMediaType, "expected `Content-Type: application/json`")
+				return
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		serverMutex.Lock()
+		defer serverMutex.Unlock()
+		sess := session.New()
+		// Session IDs have a one second resolution
+		for n := 2; fileExists(session.Path(sess.ID)); n++ {
+			sess.ID = fmt.Sprintf("%s-%d", sess.Created.Format(session.ID_FORMAT), n)
+		}
+		sess.Updated = sess.Created
+		sess.Messages = []session.Message{}
+		sess.SystemPrompt = config.SystemPrompt
+		if req.SystemPrompt != "" {
+			sess.SystemPrompt = req.SystemPrompt
+		}
+		sess.Model = config.Model
+		if req.Model != "" {
+			sess.Model = req.Model
+		}
+		if err := session.Write(sess); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, sess)
+	})
+	mux.HandleFunc("GET /sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := sessionID(w, r)
+		if !ok {
+			return
+		}
+		sess, err := session.Read(id)
+		if os.IsNotExist(err) {
+			writeError(w, http.StatusNotFound, "no such session")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, sess)
+	})
+	mux.HandleFunc("DELETE /sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := sessionID(w, r)
+		if !ok {
+			return
+		}
+		serverMutex.Lock()
+		defer serverMutex.Unlock()
+		if err := os.Remove(session.Path(id)); err != nil {
+			writeError(w, http.StatusNotFound, "no such session")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /sessions/{id}/messages", func(w http.ResponseWriter, r *http.Request) {
+		postMessage(client, config, w, r)
+	})
+	mux.HandleFunc("GET /ws", func(w http.ResponseWriter, r *http.Request) {
+		serveWebSocket(client, config, w, r)
+	})
+	return mux
+}
+
+// Sends a message in a session, the response is streamed with server-sent
+// events when `stream` is set or the client accepts `text/event-stream`
+func postMessage(client *openai.Client, config config.Config, w http.ResponseWriter, r *http.Request) {
+	id, ok := sessionID(w, r)
+	if !ok {
+		return
+	}
+	if !isJSON(r) {
+		writeError(w, http.StatusUnsupportedMediaType, "expected `Content-Type: application/json`")
+		return
+	}
+	var req messageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Content == "" {
+		writeError(w, http.StatusBadRequest, "expected a JSON body with `content`")
+		return
+	}
+	// The session is read under the lock, so that concurrent messages in the
+	// same session don't overwrite each other
+	serverMutex.Lock()
+	defer serverMutex.Unlock()
+	sess, err := session.Read(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "no such session")
+		return
+	}
+
+	stream := req.Stream || r.Header.Get("Accept") == "text/event-stream"
+	flusher, canFlush := w.(http.Flusher)
+	onChunk := func(string) {}
+	if stream {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		onChunk = func(chunk string) {
+			if chunk == "" {
+				return
+			}
+			data, _ := json.Marshal(map[string]string{"content": chunk})
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			if canFlush {
+				flusher.Flush()
+			}
+		}
+	}
+
+	response, stats, status, err := chatTurn(client, config, sess, req.Content, onChunk, &toolCallPrinter{})
+
+	result := messageResponse{Content: response, Model: stats.Model, PromptTokens: stats.PromptTokens, CompletionTokens: stats.CompletionTokens}
+	switch {
+	case stream && err != nil:
+		data, _ := json.Marshal(map[string]string{"error": err.Error()})
+		fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+	case stream:
+		data, _ := json.Marshal(result)
+		fmt.Fprintf(w, "event: done\ndata: %s\n\n", data)
+	case err != nil:
+		writeError(w, status, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// Runs a conversation turn in a session like the REPL does: filters,
+// budget, moderation, recalled memories, embedded context and tools. Returns
+// the HTTP status to report errors with.
+func chatTurn(client *openai.Client, config config.Config, sess *session.Session, prompt string, onChunk func(string), events tools.Events) (string, chat.ResponseStats, int, error) {
+	resumeSession(sess, &config)
+	prompt, ok := filterPrompt(prompt)
+	if !ok {
+		return "", chat.ResponseStats{}, http.StatusUnprocessableEntity, fmt.Errorf("the message was blocked by a filter")
+	}
+	if !checkBudget(config) {
+		return "", chat.ResponseStats{}, http.StatusPaymentRequired, fmt.Errorf("the budget is exceeded")
+	}
+	if !moderate(client, config, MODERATE_PROMPTS, prompt) {
+		return "", chat.ResponseStats{}, http.StatusUnprocessableEntity, fmt.Errorf("the message was flagged by moderation")
+	}
+	recallMemories(client, config, prompt)
+	appendMessage(session.NewMessage(openai.ChatMessageRoleUser, prompt))
+
+	req := chat.NewRequest(config, config.Model, buildMessages(config))
+	response, stats, err := tools.Stream(client, req, onChunk, events)
+	if err != nil {
+		dropLastMessage()
+		return "", stats, http.StatusBadGateway, err
+	}
+	recordUsage(stats)
+	if !moderate(client, config, MODERATE_RESPONSES, response) {
+		dropLastMessage()
+		return "", stats, http.StatusUnprocessableEntity, fmt.Errorf("the response was flagged by moderation")
+	}
+	appendMessage(newResponseMessage(response, stats))
+	maybeGenerateTitle(client, config, prompt, response)
+	compactHistory(client, config)
+	archiveSession(config)
+	return response, stats, http.StatusOK, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gpt/config"
+	"gpt/render"
+	"gpt/session"
+)
+
+var currentSession = session.New()
+
+// Writes the current conversation to the archive
+func archiveSession(config config.Config) {
+	applyGeneratedTitles()
+	if len(currentSession.Messages) == 0 {
+		return
+	}
+	currentSession.Updated = time.Now()
+	currentSession.Model = config.Model
+	currentSession.SystemPrompt = config.SystemPrompt
+	currentSession.Context = contextItems
+
+	if err := session.Write(currentSession); err != nil {
+		fmt.Printf("Error archiving session: %v\n", err)
+	}
+}
+
+// Makes an archived session the current one
+func resumeSession(sess *session.Session, config *config.Config) {
+	currentSession = sess
+	history.Reset(sess.Messages)
+	contextItems = append([]session.ContextItem{}, sess.Context...)
+	if sess.SystemPrompt != "" {
+		config.SystemPrompt = sess.SystemPrompt
+	}
+	if sess.Model != "" {
+		config.Model = sess.Model
+	}
+}
+
+func printSessions() {
+	sessions, err := session.List()
+	if err != nil {
+		fmt.Printf("Error listing sessions: %v\n", err)
+		return
+	}
+	if len(sessions) == 0 {
+		fmt.Println("No archived sessions")
+		return
+	}
+	rows := [][]string{}
+	for _, sess := range sessions {
+		rows = append(rows, []string{
+			sess.ID,
+			sess.DisplayName(),
+			sess.Updated.Format("2006-01-02 15:04"),
+			fmt.Sprint(len(sess.Messages)),
+		})
+	}
+	render.Table([]string{"ID", "Title", "Updated", "Messages"}, rows)
+}
+
+// Resumes the most recently updated session other than the current one,
+// restoring its system prompt and model
+func continueLastSession(config *config.Config) {
+	sessions, err := session.List()
+	if err != nil {
+		fmt.Printf("Error listing sessions: %v\n", err)
+		return
+	}
+	for _, sess := range sessions {
+		if sess.ID == currentSession.ID {
+			continue
+		}
+		resumeSession(sess, config)
+		fmt.Printf("Resumed `%s` (%d messages, %s)\n", sess.DisplayName(), len(history.Messages), config.Model)
+		return
+	}
+	fmt.Println("No previous session to continue")
+}
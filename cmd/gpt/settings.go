@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gpt/config"
+	"gpt/render"
+)
+
+// The API accepts at most 4 stop sequences
+const MAX_STOP_SEQUENCES = 4
+
+// Splits arguments on spaces, double-quoted arguments can contain spaces and
+// escape sequences such as `\n`
+func splitArgs(s string) ([]string, error) {
+	args := []string{}
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return args, nil
+		}
+		if s[0] != '"' {
+			arg, rest, _ := strings.Cut(s, " ")
+			args = append(args, arg)
+			s = rest
+			continue
+		}
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("unterminated quote in `%s`", s)
+		}
+		arg, _ := strconv.Unquote(quoted)
+		args = append(args, arg)
+		s = s[len(quoted):]
+	}
+}
+
+// Handles `/set <setting> [values]`, which changes a request parameter for the
+// running session without saving it in the config file
+func setParameter(config *config.Config, rest string) bool {
+	args, err := splitArgs(rest)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return true
+	}
+	if len(args) == 0 {
+		printParameters(*config)
+		return true
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "stop":
+		if len(args)-1 > MAX_STOP_SEQUENCES {
+			fmt.Printf("Error: at most %d stop sequences are supported\n", MAX_STOP_SEQUENCES)
+			return true
+		}
+		config.Stop = args[1:]
+		if len(config.Stop) == 0 {
+			fmt.Println("Stop sequences cleared")
+		} else {
+			fmt.Printf("Stop sequences: %s\n", quoteAll(config.Stop))
+		}
+	case "seed":
+		if len(args) > 2 {
+			return false
+		}
+		seed := 0
+		if len(args) == 2 {
+			if seed, err = strconv.Atoi(args[1]); err != nil {
+				fmt.Printf("Error: invalid seed `%s`\n", args[1])
+				return true
+			}
+		}
+		config.Seed = seed
+		if seed == 0 {
+			fmt.Println("Seed cleared")
+		} else {
+			fmt.Printf("Seed: %d\n", seed)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func printParameters(config config.Config) {
+	stop := "none"
+	if len(config.Stop) > 0 {
+		stop = quoteAll(config.Stop)
+	}
+	seed := "none"
+	if config.Seed != 0 {
+		seed = strconv.Itoa(config.Seed)
+	}
+	render.Table([]string{"Parameter", "Value"}, [][]string{
+		{"stop", stop},
+		{"seed", seed},
+	})
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return strings.Join(quoted, " ")
+}
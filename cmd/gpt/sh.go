@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/i18n"
+	"gpt/session"
+)
+
+// Output of commands fed back to the model is cut beyond this size
+const MAX_COMMAND_OUTPUT = 10000
+
+const SH_PROMPT = "Translate the user's request into a single shell command for %s, run with %s. " +
+	"Answer with the command only, in a single code block, without explanation. Prefer safe, non-destructive commands."
+
+func userShell() string {
+	if runtime.GOOS == "windows" {
+		return "cmd.exe"
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return filepath.Base(shell)
+	}
+	return "sh"
+}
+
+// Runs the command, showing its output as it is produced, and returns it
+func runShell(command string) (string, error) {
+	output := bytes.Buffer{}
+	cmd := shellCommand(command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &output)
+	err := cmd.Run()
+	return output.String(), err
+}
+
+func truncateOutput(output string) string {
+	if len(output) > MAX_COMMAND_OUTPUT {
+		return output[len(output)-MAX_COMMAND_OUTPUT:] + "\n[only the end of the output is shown]"
+	}
+	return output
+}
+
+// Handles `/sh "<what I want>"`: asks for a command, runs it once confirmed,
+// then offers to ask a follow-up question about its output
+func shCommand(client *openai.Client, config config.Config, request string) {
+	if !checkBudget(config) {
+		return
+	}
+	answer, err := chat.Complete(client, config.Model, fmt.Sprintf(SH_PROMPT, runtime.GOOS, userShell()), request)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	command := strings.TrimSpace(extractCodeBlock(answer))
+	fmt.Printf("\n    %s\n\n", command)
+	if !confirm(i18n.T("Run this command?")) {
+		return
+	}
+
+	output, err := runShell(command)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+	question, ok := ask(i18n.T("Follow-up question about the output (Enter to skip): "))
+	if !ok || strings.TrimSpace(question) == "" {
+		return
+	}
+	pendingInput = fmt.Sprintf("%s\n\nOutput of `%s`:\n```\n%s\n```", question, command, truncateOutput(output))
+}
+
+// Handles `!<command>`: runs it and adds its output to the context, so the
+// next prompt can refer to it
+func runCommandContext(command string) {
+	if command == "" {
+		return
+	}
+	output, err := runShell(command)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		output += fmt.Sprintf("\n[%v]", err)
+	}
+	name := "!" + command
+	addContextItem(session.ContextItem{Name: name, Kind: session.CONTEXT_COMMAND, Content: truncateOutput(output)})
+	fmt.Printf("Added the output of `%s` to the system prompt\n", command)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+
+	"gpt/config"
+	"gpt/session"
+)
+
+var slackMentionRe = regexp.MustCompile(`<@[A-Z0-9]+>`)
+
+// Implements `go-gpt serve slack [--no-cache]`. The bot connects with Socket
+// Mode, so it needs no public address: SLACK_BOT_TOKEN (xoxb-) and
+// SLACK_APP_TOKEN (xapp-) are read from `.env`.
+func runSlackBridge(args []string) {
+	fs := flag.NewFlagSet("serve slack", flag.ExitOnError)
+	noCache := fs.Bool("no-cache", false, "don't use the response cache, even if Cache is set")
+	fs.Parse(args)
+
+	client, config, shutdown := setupServer(*noCache)
+	defer shutdown()
+	botToken, appToken := os.Getenv("SLACK_BOT_TOKEN"), os.Getenv("SLACK_APP_TOKEN")
+	if botToken == "" || appToken == "" {
+		fmt.Println("Error: SLACK_BOT_TOKEN and SLACK_APP_TOKEN must be set in `.env`")
+		os.Exit(1)
+	}
+
+	api := slack.New(botToken, slack.OptionAppLevelToken(appToken))
+	socket := socketmode.New(api)
+	go func() {
+		for event := range socket.Events {
+			switch event.Type {
+			case socketmode.EventTypeConnected:
+				fmt.Println("Connected to Slack")
+			case socketmode.EventTypeEventsAPI:
+				socket.Ack(*event.Request)
+				eventsAPI, ok := event.Data.(slackevents.EventsAPIEvent)
+				if !ok {
+					continue
+				}
+				if mention, ok := eventsAPI.InnerEvent.Data.(*slackevents.AppMentionEvent); ok {
+					go answerSlackMention(client, config, api, mention)
+				}
+			}
+		}
+	}()
+	if err := socket.Run(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// Every Slack thread is a session. Session IDs can't contain the dot of the
+// thread timestamp.
+func slackSessionID(channel string, threadTs string) string {
+	return "slack-" + channel + "-" + strings.ReplaceAll(threadTs, ".", "_")
+}
+
+// Anyone in the workspace can mention the bot, so only the users of
+// `SlackUsers` and the channels of `SlackChannels` (IDs) are answered
+func slackAllowed(config config.Config, mention *slackevents.AppMentionEvent) bool {
+	return slices.Contains(config.SlackUsers, mention.User) || slices.Contains(config.SlackChannels, mention.Channel)
+}
+
+// Returns the session of a bot conversation, created with the system prompt
+// of the config the first time
+func botSession(config config.Config, id string) *session.Session {
+	if sess, err := session.Read(id); err == nil {
+		return sess
+	}
+	sess := session.New()
+	sess.ID = id
+	sess.SystemPrompt = config.SystemPrompt
+	sess.Model = config.Model
+	return sess
+}
+
+func answerSlackMention(client *openai.Client, config config.Config, api *slack.Client, mention *slackevents.AppMentionEvent) {
+	threadTs := mention.ThreadTimeStamp
+	if threadTs == "" {
+		threadTs = mention.TimeStamp
+	}
+	if !slackAllowed(config, mention) {
+		text := fmt.Sprintf("You are not allowed to use this bot, add your user ID (%s) to `SlackUsers` or this channel (%s) to `SlackChannels` to allow it", mention.User, mention.Channel)
+		if _, _, err := api.PostMessage(mention.Channel, slack.MsgOptionText(text, false), slack.MsgOptionTS(threadTs)); err != nil {
+			fmt.Printf("Error answering in Slack: %v\n", err)
+		}
+		return
+	}
+	prompt := strings.TrimSpace(slackMentionRe.ReplaceAllString(mention.Text, ""))
+	if prompt == "" {
+		return
+	}
+
+	serverMutex.Lock()
+	sess := botSession(config, slackSessionID(mention.Channel, threadTs))
+	response, _, _, err := chatTurn(client, config, sess, prompt, func(string) {}, &toolCallPrinter{})
+	serverMutex.Unlock()
+	if err != nil {
+		response = fmt.Sprintf("Error: %v", err)
+	}
+
+	_, _, err = api.PostMessage(mention.Channel, slack.MsgOptionText(response, false), slack.MsgOptionTS(threadTs))
+	if err != nil {
+		fmt.Printf("Error answering in Slack: %v\n", err)
+	}
+}
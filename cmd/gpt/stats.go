@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/provider"
+	"gpt/render"
+)
+
+func printResponseStats(s chat.ResponseStats) {
+	if s.Cached {
+		fmt.Printf("[%s] cached response\n", s.Model)
+		return
+	}
+	fmt.Printf("[%s] first token: %s, total: %s, %d tokens, %.1f tokens/s\n",
+		s.Model,
+		s.TimeToFirstToken.Round(time.Millisecond),
+		s.Duration.Round(time.Millisecond),
+		s.CompletionTokens,
+		s.TokensPerSecond(),
+	)
+	if s.Seed != 0 || s.SystemFingerprint != "" {
+		fmt.Printf("[%s] seed: %d, system fingerprint: %s\n", s.Model, s.Seed, s.SystemFingerprint)
+	}
+}
+
+type ModelUsage struct {
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+	TotalLatency     time.Duration
+	// Times to the first token of the last streamed responses, the others
+	// are slower for their length rather than for the model
+	Latencies  latencyWindow
+	SlowWarned bool
+}
+
+func (u ModelUsage) AverageLatency() time.Duration {
+	if u.Requests == 0 {
+		return 0
+	}
+	return u.TotalLatency / time.Duration(u.Requests)
+}
+
+type SessionStats struct {
+	Messages map[string]int
+	Models   map[string]*ModelUsage
+}
+
+var sessionStats = SessionStats{
+	Messages: map[string]int{},
+	Models:   map[string]*ModelUsage{},
+}
+
+func (s *SessionStats) CountMessage(role string) {
+	s.Messages[role]++
+}
+
+func (s *SessionStats) Record(r chat.ResponseStats) {
+	usage, ok := s.Models[r.Model]
+	if !ok {
+		usage = &ModelUsage{}
+		s.Models[r.Model] = usage
+	}
+	usage.Requests++
+	usage.PromptTokens += r.PromptTokens
+	usage.CompletionTokens += r.CompletionTokens
+	usage.Cost += provider.ComputeCost(r.Model, r.PromptTokens, r.CompletionTokens)
+	usage.TotalLatency += r.Duration
+	if r.TimeToFirstToken > 0 {
+		usage.Latencies.add(r.TimeToFirstToken)
+		checkSlowdown(r.Model, usage)
+	}
+}
+
+func (s *SessionStats) modelNames() []string {
+	names := make([]string, 0, len(s.Models))
+	for name := range s.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func printSessionStats() {
+	if len(sessionStats.Models) == 0 {
+		fmt.Println("No requests sent in this session yet")
+		return
+	}
+
+	// The API only reports prompt and completion tokens, so everything sent
+	// is accounted to the user and everything generated to the assistant
+	promptTokens, completionTokens := 0, 0
+	for _, usage := range sessionStats.Models {
+		promptTokens += usage.PromptTokens
+		completionTokens += usage.CompletionTokens
+	}
+	fmt.Println("Messages:")
+	render.Table([]string{"Role", "Messages", "Tokens"}, [][]string{
+		{openai.ChatMessageRoleUser, strconv.Itoa(sessionStats.Messages[openai.ChatMessageRoleUser]), strconv.Itoa(promptTokens)},
+		{openai.ChatMessageRoleAssistant, strconv.Itoa(sessionStats.Messages[openai.ChatMessageRoleAssistant]), strconv.Itoa(completionTokens)},
+	})
+
+	fmt.Println("Models:")
+	rows := [][]string{}
+	total := 0.0
+	for _, name := range sessionStats.modelNames() {
+		usage := sessionStats.Models[name]
+		total += usage.Cost
+		rows = append(rows, []string{
+			name,
+			strconv.Itoa(usage.Requests),
+			strconv.Itoa(usage.PromptTokens),
+			strconv.Itoa(usage.CompletionTokens),
+			fmt.Sprintf("$%.4f", usage.Cost),
+			usage.AverageLatency().Round(time.Millisecond).String(),
+		})
+	}
+	render.Table([]string{"Model", "Requests", "Prompt", "Completion", "Cost", "Avg latency"}, rows)
+	fmt.Printf("Total cost: $%.4f\n", total)
+
+	fmt.Println("Latency (to the first token for streamed responses):")
+	printLatencyHistogram(sessionStats.modelNames())
+}
+
+func exportSessionStats(path string) {
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Printf("Error creating `%s`: %v\n", path, err)
+		return
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"model", "requests", "prompt_tokens", "completion_tokens", "cost_usd", "avg_latency_ms", "p50_latency_ms", "p90_latency_ms", "p99_latency_ms"})
+	for _, name := range sessionStats.modelNames() {
+		usage := sessionStats.Models[name]
+		w.Write([]string{
+			name,
+			strconv.Itoa(usage.Requests),
+			strconv.Itoa(usage.PromptTokens),
+			strconv.Itoa(usage.CompletionTokens),
+			strconv.FormatFloat(usage.Cost, 'f', 6, 64),
+			strconv.FormatInt(usage.AverageLatency().Milliseconds(), 10),
+			strconv.FormatInt(percentile(usage.Latencies.list(), 0.5).Milliseconds(), 10),
+			strconv.FormatInt(percentile(usage.Latencies.list(), 0.9).Milliseconds(), 10),
+			strconv.FormatInt(percentile(usage.Latencies.list(), 0.99).Milliseconds(), 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		fmt.Printf("Error writing `%s`: %v\n", path, err)
+		return
+	}
+	fmt.Printf("Stats exported to `%s`\n", path)
+}
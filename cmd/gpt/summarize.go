@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+)
+
+// About 8k tokens
+const SUMMARY_CHUNK_SIZE = 32 * 1024
+
+const CHUNK_SUMMARY_PROMPT = "Summarize the following text, keeping the information a developer would need: " +
+	"purpose, structure, key names, values and caveats. Answer with the summary only."
+
+const COMBINE_SUMMARY_PROMPT = "The following are summaries of consecutive parts of the same document. " +
+	"Combine them into a single coherent summary. Answer with the summary only."
+
+func splitChunks(text string, size int) []string {
+	chunks := []string{}
+	for len(text) > size {
+		// Cut on a line boundary when possible
+		cut := strings.LastIndexByte(text[:size], '\n')
+		if cut <= 0 {
+			cut = size
+		}
+		chunks = append(chunks, text[:cut])
+		text = text[cut:]
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// Summarizes every chunk of the text, then combines the summaries
+func summarizeText(client *openai.Client, model string, text string) (string, error) {
+	summaries := []string{}
+	for _, chunk := range splitChunks(text, SUMMARY_CHUNK_SIZE) {
+		summary, err := chat.Complete(client, model, CHUNK_SUMMARY_PROMPT, chunk)
+		if err != nil {
+			return "", err
+		}
+		summaries = append(summaries, summary)
+	}
+	if len(summaries) == 1 {
+		return summaries[0], nil
+	}
+	return chat.Complete(client, model, COMBINE_SUMMARY_PROMPT, strings.Join(summaries, "\n\n---\n\n"))
+}
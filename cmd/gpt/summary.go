@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/session"
+)
+
+const SUMMARY_PROMPT = "You maintain the long-term memory of a conversation between a user and an assistant. " +
+	"Merge the existing summary with the messages below into a single concise summary. " +
+	"Keep facts, decisions, names, code identifiers and open questions; drop small talk. " +
+	"Answer with the summary only."
+
+func systemPrompt(config config.Config) string {
+	prompt := config.SystemPrompt + contextPrompt()
+	if memory := memoryPrompt(config); memory != "" {
+		prompt += "\n\n" + memory
+	}
+	if history.Summary != "" {
+		prompt += "\n\nSummary of the earlier conversation:\n" + history.Summary
+	}
+	return prompt
+}
+
+// Keeps at most `MaxHistoryMessages` messages in the history, folding the
+// oldest ones into the conversation summary
+func compactHistory(client *openai.Client, config config.Config) {
+	err := history.Compact(config.MaxHistoryMessages, func(summary string, dropped []session.Message) (string, error) {
+		return summarizeMessages(client, config.Model, summary, dropped)
+	})
+	if err != nil {
+		// Keep the whole history, compaction will be retried after the next message
+		fmt.Printf("Error summarizing history: %v\n", err)
+	}
+}
+
+func summarizeMessages(client *openai.Client, model string, previous string, messages []session.Message) (string, error) {
+	sb := strings.Builder{}
+	if previous != "" {
+		sb.WriteString("Existing summary:\n")
+		sb.WriteString(previous)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("Messages:\n")
+	for _, msg := range messages {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", msg.Role, msg.Content))
+	}
+
+	start := time.Now()
+	ctx, cancel := chat.Context()
+	defer cancel()
+	resp, err := client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: SUMMARY_PROMPT},
+			{Role: openai.ChatMessageRoleUser, Content: sb.String()},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("empty response")
+	}
+	recordUsage(chat.ResponseStats{
+		Model:            model,
+		Duration:         time.Since(start),
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	})
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/commands"
+	"gpt/config"
+	"gpt/session"
+)
+
+// Telegram refuses longer messages
+const TELEGRAM_MAX_MESSAGE_LENGTH = 4096
+
+// REPL commands available as bot commands, with the same description
+var telegramCommands = []string{"system", "title", "summarize"}
+
+// Implements `go-gpt serve telegram [--no-cache]`, TELEGRAM_BOT_TOKEN is read
+// from `.env`
+func runTelegramBridge(args []string) {
+	fs := flag.NewFlagSet("serve telegram", flag.ExitOnError)
+	noCache := fs.Bool("no-cache", false, "don't use the response cache, even if Cache is set")
+	fs.Parse(args)
+
+	client, config, shutdown := setupServer(*noCache)
+	defer shutdown()
+	token := os.Getenv("TELEGRAM_BOT_TOKEN")
+	if token == "" {
+		fmt.Println("Error: TELEGRAM_BOT_TOKEN must be set in `.env`")
+		os.Exit(1)
+	}
+	bot, err := tgbotapi.NewBotAPI(token)
+	if err != nil {
+		fmt.Printf("Error connecting to Telegram: %v\n", err)
+		os.Exit(1)
+	}
+
+	botCommands := []tgbotapi.BotCommand{{Command: "new", Description: "Start a new conversation"}, {Command: "help", Description: "List the commands"}}
+	for _, command := range commands.All() {
+		if slices.Contains(telegramCommands, command.Name) {
+			botCommands = append(botCommands, tgbotapi.BotCommand{Command: command.Name, Description: command.Description})
+		}
+	}
+	if _, err := bot.Request(tgbotapi.NewSetMyCommands(botCommands...)); err != nil {
+		fmt.Printf("Error registering the bot commands: %v\n", err)
+	}
+	fmt.Printf("Connected to Telegram as @%s\n", bot.Self.UserName)
+
+	updates := bot.GetUpdatesChan(tgbotapi.UpdateConfig{Timeout: 60})
+	for update := range updates {
+		if update.Message != nil {
+			go answerTelegramMessage(client, config, bot, update.Message)
+		}
+	}
+}
+
+// Anyone can talk to a bot, so only the users of `TelegramUsers` (user names
+// or IDs) are answered
+func telegramAllowed(config config.Config, user *tgbotapi.User) bool {
+	return slices.Contains(config.TelegramUsers, user.UserName) || slices.Contains(config.TelegramUsers, strconv.FormatInt(user.ID, 10))
+}
+
+func telegramSessionID(chatID int64) string {
+	return fmt.Sprintf("telegram-%d", chatID)
+}
+
+func answerTelegramMessage(client *openai.Client, config config.Config, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	reply := func(text string) {
+		for _, part := range splitMessage(text, TELEGRAM_MAX_MESSAGE_LENGTH) {
+			msg := tgbotapi.NewMessage(message.Chat.ID, part)
+			msg.ReplyToMessageID = message.MessageID
+			if _, err := bot.Send(msg); err != nil {
+				fmt.Printf("Error answering in Telegram: %v\n", err)
+			}
+		}
+	}
+	// Posts in channels have no sender
+	if message.From == nil {
+		return
+	}
+	if !telegramAllowed(config, message.From) {
+		reply(fmt.Sprintf("You are not allowed to use this bot, add your user ID (%d) to `TelegramUsers` to allow it", message.From.ID))
+		return
+	}
+
+	prompt := message.Text
+	switch {
+	case message.IsCommand():
+		reply(telegramCommand(client, config, message.Chat.ID, message.Command(), strings.TrimSpace(message.CommandArguments())))
+		return
+	case message.Voice != nil:
+		text, err := transcribeTelegramVoice(client, bot, message.Voice.FileID)
+		if err != nil {
+			reply(fmt.Sprintf("Error transcribing the voice message: %v", err))
+			return
+		}
+		reply("🎙 " + text)
+		prompt = text
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return
+	}
+
+	bot.Request(tgbotapi.NewChatAction(message.Chat.ID, tgbotapi.ChatTyping))
+	serverMutex.Lock()
+	sess := botSession(config, telegramSessionID(message.Chat.ID))
+	response, _, _, err := chatTurn(client, config, sess, prompt, func(string) {}, &toolCallPrinter{})
+	serverMutex.Unlock()
+	if err != nil {
+		response = fmt.Sprintf("Error: %v", err)
+	}
+	reply(response)
+}
+
+// Runs a bot command on the session of the chat and returns the answer
+func telegramCommand(client *openai.Client, config config.Config, chatID int64, command string, args string) string {
+	serverMutex.Lock()
+	defer serverMutex.Unlock()
+	id := telegramSessionID(chatID)
+	sess := botSession(config, id)
+
+	switch command {
+	case "new", "start":
+		// The conversation is kept, under another ID
+		if len(sess.Messages) > 0 {
+			sess.ID = id + "-" + time.Now().Format(session.ID_FORMAT)
+			if err := session.Write(sess); err != nil {
+				return fmt.Sprintf("Error: %v", err)
+			}
+			os.Remove(session.Path(id))
+		}
+		return "New conversation started"
+	case "system":
+		if args == "" {
+			return sess.SystemPrompt
+		}
+		sess.SystemPrompt = args
+	case "title":
+		if args == "" {
+			return sess.DisplayName()
+		}
+		sess.Title = args
+	case "summarize":
+		// Only URLs, files of the server are not for the users of the bot
+		if !strings.HasPrefix(args, "http://") && !strings.HasPrefix(args, "https://") {
+			return "Usage: /summarize <url>"
+		}
+		text, err := fetchDocument(args)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		summary, err := summarizeDocument(client, config.Model, text)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		return summary
+	default:
+		help := []string{"/new - Start a new conversation"}
+		for _, command := range commands.All() {
+			if slices.Contains(telegramCommands, command.Name) {
+				help = append(help, fmt.Sprintf("/%s - %s", command.Name, command.Description))
+			}
+		}
+		return strings.Join(help, "\n")
+	}
+	if err := session.Write(sess); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return "Done"
+}
+
+// Downloads a voice message and transcribes it with Whisper
+func transcribeTelegramVoice(client *openai.Client, bot *tgbotapi.BotAPI, fileID string) (string, error) {
+	url, err := bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Voice messages are Ogg Opus files, which Whisper accepts as `.oga`
+	file, err := os.CreateTemp("", "gpt-telegram-*.oga")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	_, err = io.Copy(file, resp.Body)
+	file.Close()
+	if err != nil {
+		return "", err
+	}
+	return transcribe(client, file.Name())
+}
+
+// Splits text in parts of at most `size` bytes, on line breaks when possible
+func splitMessage(text string, size int) []string {
+	parts := []string{}
+	for len(text) > size {
+		cut := strings.LastIndex(text[:size], "\n")
+		if cut <= 0 {
+			cut = size
+			for !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		parts = append(parts, text[:cut])
+		text = strings.TrimPrefix(text[cut:], "\n")
+	}
+	if text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
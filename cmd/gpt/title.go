@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/session"
+)
+
+const TITLE_PROMPT = "Give a short title (at most 6 words) for the conversation below. " +
+	"Answer with the title only, without quotes or final punctuation."
+
+type generatedTitle struct {
+	SessionID string
+	Title     string
+	Stats     chat.ResponseStats
+}
+
+// Titles are generated in the background and applied by the REPL goroutine
+// the next time the session is archived
+var generatedTitles = make(chan generatedTitle, 8)
+
+func generateTitle(client *openai.Client, config config.Config, prompt string, response string) {
+	sessionID := currentSession.ID
+	go func() {
+		start := time.Now()
+		ctx, cancel := chat.Context()
+		defer cancel()
+		resp, err := client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model: config.Model,
+			Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: TITLE_PROMPT},
+				{Role: openai.ChatMessageRoleUser, Content: fmt.Sprintf("user: %s\nassistant: %s", prompt, response)},
+			},
+		})
+		if err != nil || len(resp.Choices) == 0 {
+			return
+		}
+		title := strings.Trim(strings.TrimSpace(resp.Choices[0].Message.Content), "\"'.")
+		if title == "" {
+			return
+		}
+		generatedTitles <- generatedTitle{
+			SessionID: sessionID,
+			Title:     title,
+			Stats: chat.ResponseStats{
+				Model:            config.Model,
+				Duration:         time.Since(start),
+				PromptTokens:     resp.Usage.PromptTokens,
+				CompletionTokens: resp.Usage.CompletionTokens,
+			},
+		}
+	}()
+}
+
+// Titles the session after its first exchange
+func maybeGenerateTitle(client *openai.Client, config config.Config, prompt string, response string) {
+	if currentSession.Title == "" && len(currentSession.Messages) == 2 {
+		generateTitle(client, config, prompt, response)
+	}
+}
+
+func applyGeneratedTitles() {
+	for {
+		select {
+		case generated := <-generatedTitles:
+			recordUsage(generated.Stats)
+			if generated.SessionID == currentSession.ID {
+				if currentSession.Title == "" {
+					currentSession.Title = generated.Title
+				}
+				continue
+			}
+			// The session was switched while its title was being generated
+			sess, err := session.Read(generated.SessionID)
+			if err != nil || sess.Title != "" {
+				continue
+			}
+			sess.Title = generated.Title
+			session.Write(sess)
+		default:
+			return
+		}
+	}
+}
+
+func setTitle(config config.Config, title string) {
+	applyGeneratedTitles()
+	if title == "" {
+		if currentSession.Title == "" {
+			fmt.Println("This session has no title yet")
+		} else {
+			fmt.Println(currentSession.Title)
+		}
+		return
+	}
+	currentSession.Title = title
+	archiveSession(config)
+	fmt.Printf("Session renamed to `%s`\n", title)
+}
+
+func sessionPrompt() string {
+	if currentSession.Title == "" {
+		return ">"
+	}
+	return fmt.Sprintf("[%s] >", currentSession.Title)
+}
+
+// Turns a title into something usable as a file name
+func slugify(title string) string {
+	sb := strings.Builder{}
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteRune('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(sb.String(), "-")
+	if len(slug) > 50 {
+		slug = strings.TrimSuffix(slug[:50], "-")
+	}
+	return slug
+}
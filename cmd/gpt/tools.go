@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/i18n"
+	"gpt/render"
+)
+
+const TOOL_RESULT_PREVIEW_LENGTH = 500
+
+var (
+	toolCallStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("5"))
+	toolResultStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+)
+
+// Prints tool calls as they are streamed: the function name, then its
+// arguments as they build up
+type toolCallPrinter struct {
+	current int
+	started bool
+	// The call being written, printed once complete in accessible mode
+	pending strings.Builder
+}
+
+func (p *toolCallPrinter) Chunk(index int, name string, args string) {
+	if !p.started || index != p.current {
+		if p.started {
+			p.end()
+		}
+		p.current = index
+		p.started = true
+		if !render.Accessible {
+			fmt.Print("\n" + toolCallStyle.Render("→ "))
+		}
+	}
+	if name != "" {
+		p.write(name + "(")
+	}
+	if args != "" {
+		p.write(args)
+	}
+}
+
+func (p *toolCallPrinter) write(s string) {
+	if render.Accessible {
+		p.pending.WriteString(s)
+		return
+	}
+	fmt.Print(toolCallStyle.Render(s))
+}
+
+// Ends the call being written
+func (p *toolCallPrinter) end() {
+	if render.Accessible {
+		fmt.Println(i18n.T("Tool call:"), p.pending.String()+")")
+		p.pending.Reset()
+		return
+	}
+	fmt.Println(toolCallStyle.Render(")"))
+}
+
+func (p *toolCallPrinter) Done() {
+	if p.started {
+		p.end()
+	}
+	p.started = false
+}
+
+func (p *toolCallPrinter) Result(call openai.ToolCall, result string, cached bool) {
+	printToolResult(result, cached)
+}
+
+func printToolResult(result string, cached bool) {
+	if len(result) > TOOL_RESULT_PREVIEW_LENGTH {
+		result = truncate(result, TOOL_RESULT_PREVIEW_LENGTH) + "..."
+	}
+	if cached {
+		result = "(cached) " + result
+	}
+	if render.Accessible {
+		result = i18n.T("Tool result:") + " " + result
+	}
+	fmt.Println(toolResultStyle.Render(strings.TrimRight(result, "\n")))
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gpt/session"
+)
+
+// Marks the end of the output to wait for before logging a prompt, NUL isn't
+// printed by the REPL
+const TRANSCRIPT_SYNC = 0
+
+// Colors, cursor moves and inline images are left out of the transcript
+var escapeSequence = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|[\]_P][^\x07\x1b]*(\x07|\x1b\\)?|.)`)
+
+// The transcript tee: while it is on, os.Stdout is a pipe copied to the
+// terminal and appended to the transcript file
+var transcript *transcriptTee
+
+type transcriptTee struct {
+	path     string
+	markdown bool
+	file     *os.File
+	terminal *os.File
+	pipe     *os.File
+	// Output not written to the file yet, up to the end of the line
+	pending bytes.Buffer
+	mu      sync.Mutex
+	synced  chan struct{}
+	done    chan struct{}
+}
+
+// Starts appending what is shown to path, Markdown when it ends with .md
+func startTranscript(path string) error {
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(path, "~/") {
+		path = filepath.Join(home, path[2:])
+	}
+	// The transcript can't be encrypted as it is appended to
+	if session.Encrypted() {
+		return fmt.Errorf("the transcript would be written in plain text, while encryption is enabled")
+	}
+	if transcript != nil {
+		stopTranscript()
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	// Also for files created before
+	file.Chmod(0600)
+	r, w, err := os.Pipe()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	t := &transcriptTee{
+		path:     path,
+		markdown: ext == ".md" || ext == ".markdown",
+		file:     file,
+		terminal: os.Stdout,
+		pipe:     w,
+		synced:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	started := time.Now().Format("2006-01-02 15:04")
+	if t.markdown {
+		fmt.Fprintf(file, "# Transcript of %s\n\n", started)
+	} else {
+		fmt.Fprintf(file, "--- Transcript of %s ---\n", started)
+	}
+	go t.copy(r)
+	os.Stdout = w
+	transcript = t
+	return nil
+}
+
+// Gives the terminal back to os.Stdout once the output is written
+func stopTranscript() {
+	if transcript == nil {
+		return
+	}
+	t := transcript
+	transcript = nil
+	os.Stdout = t.terminal
+	t.pipe.Close()
+	<-t.done
+	t.file.Close()
+}
+
+func (t *transcriptTee) copy(r *os.File) {
+	defer close(t.done)
+	defer r.Close()
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		for _, part := range bytes.SplitAfter(buf[:n], []byte{TRANSCRIPT_SYNC}) {
+			synced := len(part) > 0 && part[len(part)-1] == TRANSCRIPT_SYNC
+			if synced {
+				part = part[:len(part)-1]
+			}
+			t.terminal.Write(part)
+			t.log(part, synced)
+			if synced {
+				select {
+				case t.synced <- struct{}{}:
+				default:
+				}
+			}
+		}
+		if err != nil {
+			t.log(nil, true)
+			return
+		}
+	}
+}
+
+// Writes the complete lines of output, or everything when flush is set
+func (t *transcriptTee) log(output []byte, flush bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending.Write(output)
+	end := bytes.LastIndexByte(t.pending.Bytes(), '\n') + 1
+	if flush {
+		end = t.pending.Len()
+	}
+	t.file.Write(escapeSequence.ReplaceAll(t.pending.Next(end), nil))
+}
+
+// Waits until what was printed so far is in the transcript
+func (t *transcriptTee) sync() {
+	t.pipe.Write([]byte{TRANSCRIPT_SYNC})
+	<-t.synced
+}
+
+// The terminal, for the commands run attached to it such as $EDITOR:
+// os.Stdout is a pipe while the transcript is on
+func terminalStdout() *os.File {
+	if transcript == nil {
+		return os.Stdout
+	}
+	transcript.sync()
+	return transcript.terminal
+}
+
+// Logs a line typed at the prompt, which readline echoes to the terminal
+// directly
+func logPrompt(prompt, line string) {
+	t := transcript
+	if t == nil {
+		return
+	}
+	t.sync()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.markdown {
+		fmt.Fprintf(t.file, "\n> %s\n\n", strings.ReplaceAll(line, "\n", "\n> "))
+	} else {
+		fmt.Fprintf(t.file, "%s %s\n", prompt, line)
+	}
+}
+
+// Handles `/tee [<path> | off]`
+func teeCommand(args []string) {
+	if len(args) == 0 {
+		if transcript == nil {
+			fmt.Println("The transcript is off")
+		} else {
+			fmt.Printf("Appending the transcript to %s\n", transcript.path)
+		}
+		return
+	}
+	if args[0] == "off" {
+		if transcript == nil {
+			fmt.Println("The transcript is already off")
+			return
+		}
+		path := transcript.path
+		stopTranscript()
+		fmt.Printf("Stopped appending the transcript to %s\n", path)
+		return
+	}
+	if err := startTranscript(args[0]); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("Appending the transcript to %s\n", transcript.path)
+}
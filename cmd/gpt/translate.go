@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+)
+
+const DEFAULT_TRANSLATION_LANGUAGE = "English"
+
+const TRANSLATE_PROMPT = "Detect the language of the text and translate it to %s, keeping its formatting. " +
+	"Answer with `Detected language: <language>` on the first line, followed by a blank line and the translation only."
+
+var languageNames = map[string]string{
+	"ar": "Arabic", "cs": "Czech", "da": "Danish", "de": "German", "el": "Greek",
+	"en": "English", "es": "Spanish", "fi": "Finnish", "fr": "French", "he": "Hebrew",
+	"hi": "Hindi", "hu": "Hungarian", "id": "Indonesian", "it": "Italian", "ja": "Japanese",
+	"ko": "Korean", "nl": "Dutch", "no": "Norwegian", "pl": "Polish", "pt": "Portuguese",
+	"ro": "Romanian", "ru": "Russian", "sv": "Swedish", "th": "Thai", "tr": "Turkish",
+	"uk": "Ukrainian", "vi": "Vietnamese", "zh": "Chinese",
+}
+
+// Accepts a language code or an English name, e.g. `fr` or `french`
+func parseLanguage(s string) (string, bool) {
+	if name, ok := languageNames[strings.ToLower(s)]; ok {
+		return name, true
+	}
+	for _, name := range languageNames {
+		if strings.EqualFold(name, s) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// TranslateTo, or the language of the locale, e.g. LANG=fr_FR.UTF-8
+func defaultTranslationLanguage(config config.Config) string {
+	if config.TranslateTo != "" {
+		return config.TranslateTo
+	}
+	for _, variable := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		locale := os.Getenv(variable)
+		if locale == "" {
+			continue
+		}
+		if name, ok := parseLanguage(locale[:min(2, len(locale))]); ok {
+			return name
+		}
+	}
+	return DEFAULT_TRANSLATION_LANGUAGE
+}
+
+// Handles `/translate [lang] <text | file | clipboard>`, the translation stays
+// out of the history
+func translateCommand(client *openai.Client, config config.Config, rest string) bool {
+	language := defaultTranslationLanguage(config)
+	if first, after, found := strings.Cut(rest, " "); found {
+		if name, ok := parseLanguage(first); ok {
+			language = name
+			rest = strings.TrimSpace(after)
+		}
+	}
+	rest = strings.Trim(rest, "\"")
+	if rest == "" {
+		return false
+	}
+
+	text := rest
+	switch {
+	case rest == "clipboard":
+		content, err := clipboard.ReadAll()
+		if err != nil {
+			fmt.Printf("Error reading clipboard: %v\n", err)
+			return true
+		}
+		text = content
+	case fileExists(rest):
+		content, err := readDocument(rest)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return true
+		}
+		text = content
+	}
+	if strings.TrimSpace(text) == "" {
+		fmt.Println("Error: nothing to translate")
+		return true
+	}
+	if !checkBudget(config) {
+		return true
+	}
+
+	prompt := fmt.Sprintf(TRANSLATE_PROMPT, language)
+	for i, chunk := range splitChunks(text, SUMMARY_CHUNK_SIZE) {
+		translation, err := chat.Complete(client, config.Model, prompt, chunk)
+		if err != nil {
+			fmt.Printf("Error translating: %v\n", err)
+			return true
+		}
+		// Only show the detected language once
+		if i > 0 {
+			if _, after, found := strings.Cut(translation, "\n"); found && strings.HasPrefix(translation, "Detected language:") {
+				translation = strings.TrimSpace(after)
+			}
+		}
+		fmt.Println(translation)
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"gpt/render"
+)
+
+const DATE_FORMAT = "2006-01-02"
+
+type usageGroup struct {
+	Key              string
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+}
+
+// Implements `go-gpt usage [--since DATE] [--until DATE] [--by day|model] [--csv PATH]`
+func runUsageCommand(args []string) {
+	fs := flag.NewFlagSet("usage", flag.ExitOnError)
+	since := fs.String("since", "", "only include usage from this date (YYYY-MM-DD)")
+	until := fs.String("until", "", "only include usage up to this date, inclusive (YYYY-MM-DD)")
+	by := fs.String("by", "model", "group usage by `day` or `model`")
+	csvPath := fs.String("csv", "", "export the breakdown to a CSV file instead of printing it")
+	fs.Parse(args)
+
+	if *by != "day" && *by != "model" {
+		fmt.Printf("Error: --by expects `day` or `model`, got `%s`\n", *by)
+		os.Exit(1)
+	}
+
+	var from, to time.Time
+	var err error
+	if *since != "" {
+		from, err = time.ParseInLocation(DATE_FORMAT, *since, time.Local)
+		if err != nil {
+			fmt.Printf("Error: invalid --since date `%s`\n", *since)
+			os.Exit(1)
+		}
+	}
+	if *until != "" {
+		to, err = time.ParseInLocation(DATE_FORMAT, *until, time.Local)
+		if err != nil {
+			fmt.Printf("Error: invalid --until date `%s`\n", *until)
+			os.Exit(1)
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+
+	entries, err := readUsage()
+	if err != nil {
+		fmt.Printf("Error reading usage ledger: %v\n", err)
+		os.Exit(1)
+	}
+
+	groups := map[string]*usageGroup{}
+	for _, entry := range entries {
+		if !from.IsZero() && entry.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !entry.Time.Before(to) {
+			continue
+		}
+		key := entry.Model
+		if *by == "day" {
+			key = entry.Time.Local().Format(DATE_FORMAT)
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &usageGroup{Key: key}
+			groups[key] = group
+		}
+		group.Requests++
+		group.PromptTokens += entry.PromptTokens
+		group.CompletionTokens += entry.CompletionTokens
+		group.Cost += entry.Cost
+	}
+
+	sorted := make([]*usageGroup, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	if *csvPath != "" {
+		if err := exportUsage(*csvPath, *by, sorted); err != nil {
+			fmt.Printf("Error exporting usage to `%s`: %v\n", *csvPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Usage exported to `%s`\n", *csvPath)
+		return
+	}
+
+	if len(sorted) == 0 {
+		fmt.Println("No usage recorded for this period")
+		return
+	}
+
+	header := "Model"
+	if *by == "day" {
+		header = "Day"
+	}
+	rows := [][]string{}
+	total := 0.0
+	for _, group := range sorted {
+		total += group.Cost
+		rows = append(rows, []string{
+			group.Key,
+			strconv.Itoa(group.Requests),
+			strconv.Itoa(group.PromptTokens),
+			strconv.Itoa(group.CompletionTokens),
+			fmt.Sprintf("$%.4f", group.Cost),
+		})
+	}
+	render.Table([]string{header, "Requests", "Prompt", "Completion", "Cost"}, rows)
+	fmt.Printf("Total cost: $%.4f\n", total)
+}
+
+func exportUsage(path string, by string, groups []*usageGroup) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{by, "requests", "prompt_tokens", "completion_tokens", "cost_usd"})
+	for _, group := range groups {
+		w.Write([]string{
+			group.Key,
+			strconv.Itoa(group.Requests),
+			strconv.Itoa(group.PromptTokens),
+			strconv.Itoa(group.CompletionTokens),
+			strconv.FormatFloat(group.Cost, 'f', 6, 64),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
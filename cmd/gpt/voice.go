@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+	"gpt/i18n"
+)
+
+const (
+	SPEECH_MODEL  = openai.TTSModel1
+	DEFAULT_VOICE = openai.VoiceAlloy
+)
+
+// In voice mode an empty line starts recording and responses are spoken
+var voiceMode bool
+
+func toggleVoiceMode(config config.Config) {
+	voiceMode = !voiceMode
+	if !voiceMode {
+		fmt.Println("Voice mode disabled")
+		return
+	}
+	fmt.Printf("Voice mode enabled: press Enter to talk and Enter again to send, `%svoice` to leave\n", config.CommandPrefix)
+}
+
+// Prefers sox, which works on every platform, then arecord on Linux
+func recordCommand(path string) (*exec.Cmd, error) {
+	if _, err := exec.LookPath("rec"); err == nil {
+		return exec.Command("rec", "-q", "-c", "1", "-r", "16000", path), nil
+	}
+	if _, err := exec.LookPath("arecord"); err == nil {
+		return exec.Command("arecord", "-q", "-f", "S16_LE", "-c", "1", "-r", "16000", path), nil
+	}
+	return nil, fmt.Errorf("no audio recorder found, install sox or arecord")
+}
+
+func playCommand(path string) (*exec.Cmd, error) {
+	players := [][]string{{"paplay"}, {"aplay", "-q"}, {"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet"}}
+	if runtime.GOOS == "darwin" {
+		players = append([][]string{{"afplay"}}, players...)
+	}
+	for _, player := range players {
+		if _, err := exec.LookPath(player[0]); err == nil {
+			return exec.Command(player[0], append(player[1:], path)...), nil
+		}
+	}
+	return nil, fmt.Errorf("no audio player found")
+}
+
+// Records until Enter is pressed and returns the transcript
+func recordUtterance(client *openai.Client) string {
+	file, err := os.CreateTemp("", "gpt-voice-*.wav")
+	if err != nil {
+		fmt.Printf("Error creating recording: %v\n", err)
+		return ""
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	cmd, err := recordCommand(file.Name())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return ""
+	}
+	if err := cmd.Start(); err != nil {
+		fmt.Printf("Error recording: %v\n", err)
+		return ""
+	}
+	ask(i18n.T("Recording, press Enter to stop..."))
+	// Both recorders finish writing the file when interrupted
+	cmd.Process.Signal(os.Interrupt)
+	cmd.Wait()
+
+	text, err := transcribe(client, file.Name())
+	if err != nil {
+		fmt.Printf("Error transcribing recording: %v\n", err)
+		return ""
+	}
+	return text
+}
+
+func speak(client *openai.Client, config config.Config, text string) {
+	voice := openai.SpeechVoice(config.Voice)
+	if voice == "" {
+		voice = DEFAULT_VOICE
+	}
+	ctx, cancel := chat.Context()
+	defer cancel()
+	resp, err := client.CreateSpeech(ctx, openai.CreateSpeechRequest{
+		Model:          SPEECH_MODEL,
+		Input:          text,
+		Voice:          voice,
+		ResponseFormat: openai.SpeechResponseFormatWav,
+	})
+	if err != nil {
+		fmt.Printf("Error generating speech: %v\n", err)
+		return
+	}
+	defer resp.Close()
+
+	file, err := os.CreateTemp("", "gpt-speech-*.wav")
+	if err != nil {
+		fmt.Printf("Error saving speech: %v\n", err)
+		return
+	}
+	defer os.Remove(file.Name())
+	_, err = io.Copy(file, resp)
+	file.Close()
+	if err != nil {
+		fmt.Printf("Error saving speech: %v\n", err)
+		return
+	}
+
+	cmd, err := playCommand(file.Name())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Error playing speech: %v\n", err)
+	}
+}
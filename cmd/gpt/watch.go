@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/chat"
+	"gpt/config"
+)
+
+const (
+	WATCH_POLL_INTERVAL = 500 * time.Millisecond
+	WATCH_DEBOUNCE      = time.Second
+)
+
+// Data of the watch prompt template
+type WatchedFile struct {
+	Path    string
+	Content string
+}
+
+// Implements `go-gpt watch <glob> --prompt <template> [--debounce DURATION] [--no-cache]`
+func runWatchCommand(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	prompt := fs.String("prompt", "", "prompt template, e.g. \"Review {{.Path}}: {{.Content}}\"")
+	debounce := fs.Duration("debounce", WATCH_DEBOUNCE, "time without changes to wait for before running the prompt")
+	noCache := fs.Bool("no-cache", false, "don't use the response cache, even if Cache is set")
+	// The glob comes first, flags are parsed after it
+	if len(args) == 0 || len(args[0]) > 0 && args[0][0] == '-' {
+		fmt.Println("Usage: go-gpt watch <glob> --prompt <template> [--debounce DURATION] [--no-cache]")
+		os.Exit(1)
+	}
+	pattern := args[0]
+	fs.Parse(args[1:])
+	if *prompt == "" {
+		fmt.Println("Error: missing --prompt")
+		os.Exit(1)
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		fmt.Printf("Error: invalid glob `%s`: %v\n", pattern, err)
+		os.Exit(1)
+	}
+
+	client, config, shutdown := setupServer(*noCache)
+	defer shutdown()
+
+	// Files are polled, like the watched context items, so that files created
+	// after the start are picked up too
+	modTimes := watchGlob(pattern)
+	fmt.Printf("Watching %d files matching `%s`, press Ctrl-C to stop\n", len(modTimes), pattern)
+	changed := map[string]bool{}
+	lastChange := time.Time{}
+	for range time.Tick(WATCH_POLL_INTERVAL) {
+		current := watchGlob(pattern)
+		for path, modTime := range current {
+			if previous, ok := modTimes[path]; !ok || !previous.Equal(modTime) {
+				changed[path] = true
+				lastChange = time.Now()
+			}
+		}
+		modTimes = current
+		// Editors often write files several times in a row
+		if len(changed) == 0 || time.Since(lastChange) < *debounce {
+			continue
+		}
+		paths := make([]string, 0, len(changed))
+		for path := range changed {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			runWatchPrompt(client, config, *prompt, path)
+		}
+		changed = map[string]bool{}
+	}
+}
+
+// Modification times of the files matching the glob
+func watchGlob(pattern string) map[string]time.Time {
+	modTimes := map[string]time.Time{}
+	paths, _ := filepath.Glob(pattern)
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			modTimes[path] = info.ModTime()
+		}
+	}
+	return modTimes
+}
+
+func runWatchPrompt(client *openai.Client, config config.Config, prompt string, path string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error reading `%s`: %v\n", path, err)
+		return
+	}
+	prompt, err = renderTemplate("watch", prompt, WatchedFile{Path: path, Content: string(content)})
+	if err != nil {
+		fmt.Printf("Error rendering the prompt: %v\n", err)
+		return
+	}
+	prompt, ok := filterPrompt(prompt)
+	if !ok || !checkBudget(config) {
+		return
+	}
+
+	fmt.Printf("\n[%s] %s changed\n", time.Now().Format("15:04:05"), path)
+	req := chat.NewRequest(config, config.Model, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: config.SystemPrompt},
+		{Role: openai.ChatMessageRoleUser, Content: prompt},
+	})
+	printer := &responsePrinter{}
+	_, _, stats, err := chat.StreamCompletion(client, req, func(chunk string) { printer.chunk(chunk) }, nil)
+	printer.done()
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	recordUsage(stats)
+}
@@ -0,0 +1,133 @@
+// Minimal chat client for the REST API of `serve`
+let currentSession = null;
+
+// The bearer token of the API, given once as `/#token=<token>`
+const hashToken = new URLSearchParams(location.hash.slice(1)).get("token");
+if (hashToken) {
+  localStorage.setItem("token", hashToken);
+  history.replaceState(null, "", location.pathname);
+}
+const token = localStorage.getItem("token") || "";
+
+function api(path, options = {}) {
+  return fetch(path, { ...options, headers: { ...options.headers, Authorization: `Bearer ${token}` } });
+}
+
+const sessionsList = document.getElementById("sessions");
+const messagesView = document.getElementById("messages");
+const input = document.getElementById("input");
+
+function escapeHTML(text) {
+  return text.replace(/[&<>"']/g, (c) => ({ "&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;" })[c]);
+}
+
+// Only code blocks are formatted, the rest is shown as is
+function format(text) {
+  return escapeHTML(text).replace(/```\w*\n?([\s\S]*?)```/g, "<pre><code>$1</code></pre>");
+}
+
+function addMessage(role, content) {
+  const div = document.createElement("div");
+  div.className = "message " + role;
+  div.innerHTML = `<div class="role">${role}</div><div class="content"></div>`;
+  div.querySelector(".content").innerHTML = format(content);
+  messagesView.appendChild(div);
+  messagesView.scrollTop = messagesView.scrollHeight;
+  return div;
+}
+
+async function loadSessions() {
+  const sessions = await (await api("/sessions")).json();
+  sessionsList.innerHTML = "";
+  for (const session of sessions) {
+    const li = document.createElement("li");
+    li.textContent = session.title || session.id;
+    li.title = `${session.messages} messages`;
+    li.classList.toggle("active", session.id === currentSession);
+    li.onclick = () => openSession(session.id);
+    sessionsList.appendChild(li);
+  }
+}
+
+async function openSession(id) {
+  currentSession = id;
+  const session = await (await api(`/sessions/${id}`)).json();
+  messagesView.innerHTML = "";
+  for (const message of session.messages || []) {
+    addMessage(message.role, message.content);
+  }
+  loadSessions();
+}
+
+async function newSession() {
+  const session = await (await api("/sessions", { method: "POST" })).json();
+  await openSession(session.id);
+}
+
+async function send(content) {
+  if (!currentSession) {
+    await newSession();
+  }
+  addMessage("user", content);
+  const reply = addMessage("assistant", "");
+  const target = reply.querySelector(".content");
+  let text = "";
+
+  const response = await api(`/sessions/${currentSession}/messages`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ content, stream: true }),
+  });
+  if (!response.ok) {
+    const body = await response.json();
+    reply.classList.add("error");
+    target.textContent = body.error;
+    return;
+  }
+
+  // Server-sent events, separated by blank lines
+  const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+  let buffer = "";
+  for (;;) {
+    const { value, done } = await reader.read();
+    if (done) {
+      break;
+    }
+    buffer += value;
+    const events = buffer.split("\n\n");
+    buffer = events.pop();
+    for (const event of events) {
+      const type = (event.match(/^event: (.*)$/m) || [])[1] || "message";
+      const data = JSON.parse((event.match(/^data: (.*)$/m) || [])[1] || "{}");
+      if (type === "error") {
+        reply.classList.add("error");
+        target.textContent = data.error;
+      } else if (type === "message") {
+        text += data.content;
+        target.innerHTML = format(text);
+        messagesView.scrollTop = messagesView.scrollHeight;
+      }
+    }
+  }
+  loadSessions();
+}
+
+document.getElementById("new-session").onclick = newSession;
+
+document.getElementById("composer").onsubmit = (e) => {
+  e.preventDefault();
+  const content = input.value.trim();
+  if (content) {
+    input.value = "";
+    send(content);
+  }
+};
+
+input.onkeydown = (e) => {
+  if (e.key === "Enter" && !e.shiftKey) {
+    e.preventDefault();
+    document.getElementById("composer").requestSubmit();
+  }
+};
+
+loadSessions();
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"gpt/config"
+)
+
+const WEBHOOK_TIMEOUT = 10 * time.Second
+
+// Events sent to webhooks
+const (
+	EVENT_REVIEW    = "review"
+	EVENT_COMPARE   = "compare"
+	EVENT_SUMMARIZE = "summarize"
+)
+
+type webhookPayload struct {
+	Event  string    `json:"event"`
+	Time   time.Time `json:"time"`
+	Result any       `json:"result"`
+}
+
+// Posts the result of an event to the webhooks subscribed to it, and waits
+// for them so that nothing is lost when the program exits
+func notifyWebhooks(config config.Config, event string, result any) {
+	data, err := json.Marshal(webhookPayload{Event: event, Time: time.Now(), Result: result})
+	if err != nil {
+		fmt.Printf("Error encoding the webhook payload: %v\n", err)
+		return
+	}
+	client := http.Client{Timeout: WEBHOOK_TIMEOUT}
+	wg := sync.WaitGroup{}
+	for _, webhook := range config.Webhooks {
+		if len(webhook.Events) > 0 && !slices.Contains(webhook.Events, event) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(data))
+			if err != nil {
+				fmt.Printf("Error notifying `%s`: %v\n", webhook.URL, err)
+				return
+			}
+			req.Header.Set("Content-Type", "application/json")
+			for name, value := range webhook.Headers {
+				req.Header.Set(name, value)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				fmt.Printf("Error notifying `%s`: %v\n", webhook.URL, err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= 300 {
+				fmt.Printf("Error notifying `%s`: %s\n", webhook.URL, resp.Status)
+			}
+		}()
+	}
+	wg.Wait()
+}
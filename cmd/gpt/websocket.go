@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/config"
+	"gpt/session"
+)
+
+// Frames sent and received on the WebSocket. Clients send `message` frames
+// with a session and content, and receive `token`, `tool_call`,
+// `tool_result`, then `done` or `error` frames.
+type wsFrame struct {
+	Type             string `json:"type"`
+	Session          string `json:"session,omitempty"`
+	Content          string `json:"content,omitempty"`
+	Index            *int   `json:"index,omitempty"`
+	Name             string `json:"name,omitempty"`
+	Arguments        string `json:"arguments,omitempty"`
+	Result           string `json:"result,omitempty"`
+	Cached           bool   `json:"cached,omitempty"`
+	Model            string `json:"model,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens,omitempty"`
+	CompletionTokens int    `json:"completion_tokens,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// Cross-origin connections are refused, like the default of the upgrader
+var upgrader = websocket.Upgrader{}
+
+// Forwards the tool events of a turn as frames
+type wsToolEvents struct {
+	conn *websocket.Conn
+}
+
+func (e *wsToolEvents) Chunk(index int, name string, args string) {
+	e.conn.WriteJSON(wsFrame{Type: "tool_call", Index: &index, Name: name, Arguments: args})
+}
+
+func (e *wsToolEvents) Done() {}
+
+func (e *wsToolEvents) Result(call openai.ToolCall, result string, cached bool) {
+	e.conn.WriteJSON(wsFrame{Type: "tool_result", Name: call.Function.Name, Result: result, Cached: cached})
+}
+
+func serveWebSocket(client *openai.Client, config config.Config, w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	for {
+		var frame wsFrame
+		if err := conn.ReadJSON(&frame); err != nil {
+			return
+		}
+		if frame.Type != "message" || frame.Session == "" || frame.Content == "" {
+			conn.WriteJSON(wsFrame{Type: "error", Error: "expected a `message` frame with a `session` and `content`"})
+			continue
+		}
+		wsMessage(client, config, conn, frame)
+	}
+}
+
+func wsMessage(client *openai.Client, config config.Config, conn *websocket.Conn, frame wsFrame) {
+	serverMutex.Lock()
+	defer serverMutex.Unlock()
+	sess, err := session.Read(frame.Session)
+	if err != nil {
+		conn.WriteJSON(wsFrame{Type: "error", Session: frame.Session, Error: "no such session"})
+		return
+	}
+	onChunk := func(chunk string) {
+		if chunk != "" {
+			conn.WriteJSON(wsFrame{Type: "token", Content: chunk})
+		}
+	}
+	response, stats, _, err := chatTurn(client, config, sess, frame.Content, onChunk, &wsToolEvents{conn: conn})
+	if err != nil {
+		conn.WriteJSON(wsFrame{Type: "error", Session: frame.Session, Error: err.Error()})
+		return
+	}
+	conn.WriteJSON(wsFrame{
+		Type:             "done",
+		Session:          frame.Session,
+		Content:          response,
+		Model:            stats.Model,
+		PromptTokens:     stats.PromptTokens,
+		CompletionTokens: stats.CompletionTokens,
+	})
+}
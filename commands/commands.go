@@ -0,0 +1,100 @@
+// Package commands is the registry of the REPL commands: the built-in ones
+// register themselves like any other.
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"gpt/config"
+	"gpt/i18n"
+)
+
+// What a handler gets: the API client, the config of the session, which it
+// can change, and what was typed
+type Context struct {
+	Client *openai.Client
+	Config *config.Config
+	// The words of the command line, starting with the command name
+	Args []string
+	// Everything typed after the command name, without surrounding quotes
+	Rest string
+	// Set by the handler to leave the REPL
+	Exit bool
+}
+
+type Handler func(ctx *Context)
+
+type Command struct {
+	Name        string
+	Args        []string
+	Description string
+	Handler     Handler
+	Details
+}
+
+// Commands in registration order, which is the order of `/help`
+var registry []Command
+
+// Adds a REPL command, or replaces the command with the same name. args are
+// completed after the name and listed by `/help` along with help.
+func Register(name string, args []string, help string, handler Handler) {
+	command := Command{Name: name, Args: args, Description: help, Handler: handler}
+	for i, existing := range registry {
+		if existing.Name == name {
+			registry[i] = command
+			return
+		}
+	}
+	registry = append(registry, command)
+}
+
+func Lookup(name string) (Command, bool) {
+	for _, command := range registry {
+		if command.Name == name {
+			return withDetails(command), true
+		}
+	}
+	return Command{}, false
+}
+
+// Returns the registered commands, in registration order
+func All() []Command {
+	commands := []Command{}
+	for _, command := range registry {
+		commands = append(commands, withDetails(command))
+	}
+	return commands
+}
+
+// Runs a command line typed without its prefix, e.g. `embed main.go`
+func Run(ctx *Context, line string) {
+	ctx.Args = []string{}
+	for _, str := range strings.Split(line, " ") {
+		if str != "" {
+			ctx.Args = append(ctx.Args, str)
+		}
+	}
+	if len(ctx.Args) == 0 {
+		fmt.Println(i18n.T("Error: missing command name"))
+		return
+	}
+	command, ok := Lookup(ctx.Args[0])
+	if !ok {
+		prefix := ctx.Config.CommandPrefix
+		if suggestion, ok := Closest(ctx.Args[0], names()); ok {
+			fmt.Print(i18n.T("Error: `%s%s` is not a command, did you mean `%s%s`?\n", prefix, ctx.Args[0], prefix, suggestion))
+		} else {
+			fmt.Print(i18n.T("Error: `%s%s` is not a command, see `%shelp`\n", prefix, ctx.Args[0], prefix))
+		}
+		return
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), ctx.Args[0]))
+	if len(rest) >= 2 && rest[0] == '"' && rest[len(rest)-1] == '"' {
+		rest = rest[1 : len(rest)-1]
+	}
+	ctx.Rest = rest
+	command.Handler(ctx)
+}
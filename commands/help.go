@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"gpt/i18n"
+)
+
+// Groups of `/help`, in order
+const (
+	GROUP_SESSION  = "session"
+	GROUP_CONTEXT  = "context"
+	GROUP_OUTPUT   = "output"
+	GROUP_SETTINGS = "settings"
+	GROUP_TOOLS    = "tools"
+	// Commands described without a group, or not described at all
+	GROUP_OTHER = "other"
+)
+
+var Groups = []string{GROUP_SESSION, GROUP_CONTEXT, GROUP_OUTPUT, GROUP_SETTINGS, GROUP_TOOLS, GROUP_OTHER}
+
+// What `/help <command>` shows besides the description
+type Details struct {
+	Group string
+	// Forms of the arguments, e.g. `<path> [--mask <mask>]`
+	Usage []string
+	// Command lines without the prefix, e.g. `embed main.go:120-200`
+	Examples []string
+}
+
+var details = map[string]Details{}
+
+// Sets the group and details of a command, whether it is registered yet or not
+func Describe(name string, d Details) {
+	details[name] = d
+}
+
+func withDetails(command Command) Command {
+	command.Details = details[command.Name]
+	if command.Group == "" {
+		command.Group = GROUP_OTHER
+	}
+	return command
+}
+
+// Prints the commands by group, the commands of a group, or the details of a
+// command
+func PrintHelp(prefix string, topic string) {
+	commands := All()
+	commandStrings := buildCommandStrings(commands, prefix)
+	// Columns are aligned across groups
+	maxLength := findMaxLength(commandStrings)
+
+	if topic == "" {
+		fmt.Println(i18n.T("Help:"))
+		for _, group := range Groups {
+			printGroup(commands, commandStrings, maxLength, group)
+		}
+		fmt.Print(i18n.T("Use `%shelp <group>` or `%shelp <command>` for details\n", prefix, prefix))
+		return
+	}
+	if slices.Contains(Groups, topic) {
+		printGroup(commands, commandStrings, maxLength, topic)
+		return
+	}
+	command, ok := Lookup(strings.TrimPrefix(topic, prefix))
+	if !ok {
+		if suggestion, ok := Closest(strings.TrimPrefix(topic, prefix), append(names(), Groups...)); ok {
+			fmt.Print(i18n.T("Error: `%s` is neither a group nor a command, did you mean `%s`?\n", topic, suggestion))
+		} else {
+			fmt.Print(i18n.T("Error: `%s` is neither a group (%s) nor a command\n", topic, strings.Join(Groups, ", ")))
+		}
+		return
+	}
+	printDetails(command, prefix)
+}
+
+func printGroup(commands []Command, commandStrings []string, maxLength int, group string) {
+	members, memberStrings := []Command{}, []string{}
+	for i, command := range commands {
+		if command.Group == group {
+			members = append(members, command)
+			memberStrings = append(memberStrings, commandStrings[i])
+		}
+	}
+	if len(members) == 0 {
+		return
+	}
+	fmt.Printf("%s:\n", i18n.T(strings.ToUpper(group[:1])+group[1:]))
+	printFormattedHelp(members, memberStrings, maxLength)
+}
+
+func printDetails(command Command, prefix string) {
+	fmt.Println(i18n.T(command.Description))
+	fmt.Println(i18n.T("Usage:"))
+	if len(command.Usage) == 0 {
+		fmt.Printf("    %s%s\n", prefix, command.Name)
+	}
+	for _, usage := range command.Usage {
+		fmt.Println(strings.TrimRight(fmt.Sprintf("    %s%s %s", prefix, command.Name, usage), " "))
+	}
+	if len(command.Examples) > 0 {
+		fmt.Println(i18n.T("Examples:"))
+		for _, example := range command.Examples {
+			fmt.Printf("    %s%s\n", prefix, example)
+		}
+	}
+}
+
+func buildCommandStrings(commands []Command, prefix string) []string {
+	var result []string
+	for _, cmd := range commands {
+		sb := strings.Builder{}
+		sb.WriteString(fmt.Sprintf("    %s%s ", prefix, cmd.Name))
+
+		if len(cmd.Args) > 0 {
+			sb.WriteString("<")
+			sb.WriteString(strings.Join(cmd.Args, " | "))
+			sb.WriteString(">")
+		}
+
+		result = append(result, sb.String())
+	}
+	return result
+}
+
+// Lengths are display widths, a CJK character takes two columns
+func findMaxLength(strings []string) int {
+	maxLen := 0
+	for _, s := range strings {
+		maxLen = max(maxLen, runewidth.StringWidth(s))
+	}
+	return maxLen
+}
+
+func printFormattedHelp(commands []Command, cmdStrings []string, maxLen int) {
+	for i, cmd := range commands {
+		fmt.Printf("%s", cmdStrings[i])
+		padding := maxLen - runewidth.StringWidth(cmdStrings[i]) + 1
+		fmt.Printf("%*s%s\n", padding, "", i18n.T(cmd.Description))
+	}
+}
@@ -0,0 +1,55 @@
+package commands
+
+import "strings"
+
+// Typos further than this from every candidate get no suggestion
+const MAX_SUGGESTION_DISTANCE = 2
+
+// Returns the candidate closest to name by edit distance, ignoring case, for
+// "did you mean" suggestions
+func Closest(name string, candidates []string) (string, bool) {
+	best, bestDistance := "", MAX_SUGGESTION_DISTANCE+1
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		// Short names are within 2 edits of too many others
+		if distance < bestDistance && distance < len(name) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best, best != ""
+}
+
+// Edit distance in runes, counting the swap of two adjacent runes as one edit
+// (optimal string alignment)
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func names() []string {
+	names := []string{}
+	for _, command := range registry {
+		names = append(names, command.Name)
+	}
+	return names
+}
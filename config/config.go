@@ -0,0 +1,185 @@
+// Package config holds the settings of go-gpt, stored as TOML, YAML or JSON.
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type Config struct {
+	Model              string
+	RenderMarkdown     bool
+	Theme              string
+	SystemPrompt       string
+	DefaultHistoryPath string
+	CommandPrefix      string
+	ShowStats          bool
+	SessionBudget      float64
+	MonthlyBudget      float64
+	Pricing            map[string]ModelPrice
+	CompareModels      []string
+	MaxHistoryMessages int
+	MemoryRecall       int
+	EmbeddingModel     string
+	RecentSessions     int
+	// Histories, sessions and memories are encrypted when one of these is set
+	EncryptionPassphrase string
+	EncryptionKeyFile    string
+	MaxEmbedSize         int64
+	ImageModel           string
+	ImageSize            string
+	ImageQuality         string
+	ImagesDir            string
+	ImagePreview         string
+	Voice                string
+	Moderation           string
+	ModerationAction     string
+	Filters              []ContentFilter
+	Stop                 []string
+	Seed                 int
+	// Sent with every request when not 0
+	Temperature float64
+	MaxTokens   int
+	// Limit of every API request, including the streaming of the response, e.g.
+	// "2m". 0 disables it.
+	Timeout Duration
+	// The execute_code tool is only available when enabled
+	CodeExecution   bool
+	CodeTimeout     int
+	CodeMemoryLimit int
+	CodeNetwork     bool
+	// Root of the files the model can read and write, tools are disabled when empty
+	Workspace string
+	// Domains the http_request tool can reach, it is disabled when empty
+	HTTPAllowList []string
+	TranslateTo   string
+	FixCommand    string
+	DiffStyle     string
+	// Limits of batch operations and API requests, 0 disables a limit
+	Concurrency       int
+	RequestsPerMinute int
+	TokensPerMinute   int
+	// Identical requests are answered from the response cache
+	Cache bool
+	// Run `/health` on startup
+	HealthCheck bool
+	// OTLP/HTTP collector receiving the spans, tracing is disabled when empty
+	TracingEndpoint string
+	// Bearer token of the `serve` API, GPT_SERVER_TOKEN overrides it
+	ServerToken string
+	// Let `serve` and the bots use CodeExecution, Workspace and HTTPAllowList
+	ServerTools bool
+	// User and channel IDs where the Slack bot answers
+	SlackUsers    []string
+	SlackChannels []string
+	// System prompts of the Discord bot, by channel ID
+	DiscordSystemPrompts map[string]string
+	// User names or IDs allowed to talk to the Discord bot, and channel IDs
+	// where everyone can
+	DiscordUsers    []string
+	DiscordChannels []string
+	// User names or IDs allowed to talk to the Telegram bot
+	TelegramUsers []string
+	Webhooks      []Webhook
+	Schedules     []Schedule
+	// Offer new clipboard content as context from the start
+	WatchClipboard bool
+	// Language of the REPL messages: en, fr, es or auto for the locale
+	Language string
+	// Screen reader friendly output: complete messages announced by their
+	// role, no redraws or box drawing
+	Accessible bool
+	// Copy each completed response to the clipboard
+	AutoCopy bool
+	// Format of `/copy` and AutoCopy: markdown, plain or html
+	CopyFormat string
+	// Log of the REPL appended to on startup, Markdown when it ends with .md
+	Transcript string
+	// Config files read before this one, which overrides them
+	Include []string
+	// Files embedded on startup, relative to the config file. The files of
+	// every layer are embedded.
+	Embed []string
+}
+
+// A time.Duration written as a string such as "1m30s" in every format
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	*d = Duration(duration)
+	return err
+}
+
+// Prices are in USD per million tokens
+type ModelPrice struct {
+	Input  float64
+	Output float64
+}
+
+// A deny-list entry applied to outgoing prompts, e.g. an internal hostname.
+// Action is block (the default), warn or redact.
+type ContentFilter struct {
+	// Regular expression, use `(?i)` for case-insensitive keywords
+	Pattern string
+	Action  string
+}
+
+// A URL notified when long operations complete. Events lists the events to
+// send, all of them when empty, and Headers are added to the requests, e.g.
+// for authentication.
+type Webhook struct {
+	URL     string
+	Events  []string
+	Headers map[string]string
+}
+
+// A prompt run on a cron schedule, e.g. `0 9 * * *` for every day at 9:00.
+// Prompt and Output are templates. The result is written to Output when set,
+// and sent to the webhooks of the "schedule" event.
+type Schedule struct {
+	Name   string
+	Cron   string
+	Prompt string
+	Output string
+	// Model of the config when empty
+	Model string
+}
+
+// Built-in values of the fields. The zero values of the other fields select
+// their defaults, e.g. 4 for Concurrency or "alloy" for Voice.
+func Default() Config {
+	return Config{
+		Model:          "gpt-4o-mini",
+		RenderMarkdown: true,
+		Theme:          "dark",
+		SystemPrompt:   "You are a terminal-based chat assistant. Give relatively short answers, while being as accurate as possible.",
+		CommandPrefix:  "/",
+	}
+}
+
+func Load(path string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	err = unmarshal(path, data, &config)
+	return config, err
+}
+
+func Save(path string, config Config) error {
+	data, err := marshal(path, config)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Extensions of the config formats, in order of precedence when several
+// config files exist
+var Extensions = []string{".toml", ".yaml", ".yml", ".json"}
+
+// Returns the config file named name with the first extension that exists,
+// or the TOML file when there is none
+func Find(name string) string {
+	for _, ext := range Extensions {
+		if fileExists(name + ext) {
+			return name + ext
+		}
+	}
+	return name + Extensions[0]
+}
+
+// The format is chosen by the extension of path. Keys are the field names in
+// every format, as in TOML.
+func unmarshal(path string, data []byte, config *Config) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return toml.Unmarshal(data, config)
+	case ".json":
+		return json.Unmarshal(data, config)
+	case ".yaml", ".yml":
+		// yaml.v3 expects lowercase keys, JSON matches the field names
+		var fields map[string]any
+		if err := yaml.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, config)
+	}
+	return fmt.Errorf("unsupported config format `%s`", filepath.Ext(path))
+}
+
+func marshal(path string, config Config) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return toml.Marshal(config)
+	case ".json":
+		return json.MarshalIndent(config, "", "  ")
+	case ".yaml", ".yml":
+		data, err := json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(fields)
+	}
+	return nil, fmt.Errorf("unsupported config format `%s`", filepath.Ext(path))
+}
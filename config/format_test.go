@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Empty and nil slices are equal, TOML doesn't tell them apart
+func differentFields(a Config, b Config) []string {
+	fields := []string{}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := range va.NumField() {
+		fa, fb := va.Field(i), vb.Field(i)
+		if fa.Kind() == reflect.Slice && fa.Len() == 0 && fb.Len() == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			fields = append(fields, va.Type().Field(i).Name)
+		}
+	}
+	return fields
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	config := Default()
+	config.Timeout = Duration(90 * time.Second)
+	config.Temperature = 0.5
+	config.Stop = []string{"END"}
+	config.Pricing = map[string]ModelPrice{"gpt-4o": {Input: 2.5, Output: 10}}
+	config.Filters = []ContentFilter{{Pattern: "(?i)secret", Action: "redact"}}
+	config.Webhooks = []Webhook{{URL: "https://example.com", Events: []string{"compare"}, Headers: map[string]string{"Authorization": "Bearer x"}}}
+	config.DiscordSystemPrompts = map[string]string{"123": "Be brief"}
+
+	for _, ext := range Extensions {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config"+ext)
+			if err := Save(path, config); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, field := range differentFields(loaded, config) {
+				t.Errorf("%s: got %v, want %v", field, reflect.ValueOf(loaded).FieldByName(field), reflect.ValueOf(config).FieldByName(field))
+			}
+		})
+	}
+}
+
+func TestLoadFieldNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"config.toml", "Model = \"gpt-4o\"\nTimeout = \"2m\"\n"},
+		{"config.yaml", "Model: gpt-4o\nTimeout: 2m\n"},
+		{"config.json", `{"Model": "gpt-4o", "Timeout": "2m"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), test.name)
+			writeFile(t, path, test.content)
+			config, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.Model != "gpt-4o" || config.Timeout != Duration(2*time.Minute) {
+				t.Errorf("got Model %q and Timeout %s", config.Model, config.Timeout)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "config")
+	if got := Find(name); got != name+".toml" {
+		t.Errorf("without files, got %s", got)
+	}
+	writeFile(t, name+".json", "{}")
+	writeFile(t, name+".yaml", "")
+	if got := Find(name); got != name+".yaml" {
+		t.Errorf("YAML should win over JSON, got %s", got)
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Name of the project config, found in the working directory or one of its
+// parents, e.g. `.gpt.toml` or `.gpt.yaml`
+const PROJECT_NAME = ".gpt"
+
+// Returns the path of the closest project config, starting from dir
+func FindProject(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if path := Find(filepath.Join(dir, PROJECT_NAME)); fileExists(path) {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Fields a project config can set. A cloned repository could otherwise run
+// commands, e.g. with FixCommand, or send data off the machine, e.g. with
+// Webhooks, as soon as the client is started in it.
+var projectFields = []string{"Model", "SystemPrompt", "Embed", "Include"}
+
+// Loads the config file at path, then the project config of dir over it.
+// Returns the config and the files it was read from, in order.
+func LoadLayers(path string, dir string) (Config, []string, error) {
+	var config Config
+	files := []string{}
+	if err := loadLayer(path, &config, &files, nil, false); err != nil {
+		return config, files, err
+	}
+	if project, ok := FindProject(dir); ok {
+		if err := loadLayer(project, &config, &files, nil, true); err != nil {
+			return config, files, err
+		}
+	}
+	return config, files, nil
+}
+
+// Reads a config file over config, only the fields it sets are changed. The
+// files it includes are read first, and the files it embeds are added to the
+// ones of the previous layers. stack holds the files including this one, a
+// file can be included by several others but not by itself. Project layers
+// can only set projectFields and embed files of their directory.
+func loadLayer(path string, config *Config, files *[]string, stack []string, project bool) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(stack, path) {
+		return fmt.Errorf("`%s` includes itself", path)
+	}
+	if !slices.Contains(*files, path) {
+		*files = append(*files, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var layer Config
+	if err := unmarshal(path, data, &layer); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	for _, include := range layer.Include {
+		if err := loadLayer(resolvePath(path, include), config, files, append(stack, path), project); err != nil {
+			return err
+		}
+	}
+
+	embed := config.Embed
+	if project {
+		if err := checkProjectLayer(path, layer); err != nil {
+			return err
+		}
+		if layer.Model != "" {
+			config.Model = layer.Model
+		}
+		if layer.SystemPrompt != "" {
+			config.SystemPrompt = layer.SystemPrompt
+		}
+	} else if err := unmarshal(path, data, config); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	config.Include = nil
+	config.Embed = embed
+	for _, file := range layer.Embed {
+		if file = resolvePath(path, file); !slices.Contains(config.Embed, file) {
+			config.Embed = append(config.Embed, file)
+		}
+	}
+	return nil
+}
+
+func checkProjectLayer(path string, layer Config) error {
+	refused := []string{}
+	v := reflect.ValueOf(layer)
+	for i := range v.NumField() {
+		if name := v.Type().Field(i).Name; !v.Field(i).IsZero() && !slices.Contains(projectFields, name) {
+			refused = append(refused, name)
+		}
+	}
+	if len(refused) > 0 {
+		return fmt.Errorf("%s: project configs can only set %s, not %s", path, strings.Join(projectFields, ", "), strings.Join(refused, ", "))
+	}
+	// Symlinks are resolved, a repository could otherwise link to any file
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	for _, file := range layer.Embed {
+		if filepath.IsAbs(file) || !insideDir(dir, filepath.Join(dir, file)) {
+			return fmt.Errorf("%s: project configs can only embed files of their directory, not `%s`", path, file)
+		}
+	}
+	return nil
+}
+
+// Whether path, once its symlinks are resolved, is in dir. The symlinks of
+// missing files are resolved up to their closest existing parent.
+func insideDir(dir string, path string) bool {
+	missing := ""
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			path = filepath.Join(resolved, missing)
+			break
+		}
+		parent := filepath.Dir(path)
+		if !os.IsNotExist(err) || parent == path {
+			return false
+		}
+		missing = filepath.Join(filepath.Base(path), missing)
+		path = parent
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// Paths in a config file are relative to it. They are returned relative to the
+// working directory when possible, as they are displayed.
+func resolvePath(configPath string, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(configPath), path)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, path); err == nil {
+			return rel
+		}
+	}
+	return path
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	writeFile(t, "home/base.toml", "Model = \"base\"\nShowStats = true\nEmbed = [\"notes.md\"]\n")
+	writeFile(t, "home/config.yaml", "Include: [base.toml]\nModel: user\nSystemPrompt: user prompt\n")
+	writeFile(t, "project/.gpt.json", `{"SystemPrompt": "project prompt", "Embed": ["README.md"]}`)
+	if err := os.Mkdir(filepath.Join("project", "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	config, files, err := LoadLayers(filepath.Join("home", "config.yaml"), filepath.Join("project", "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Model != "user" || config.SystemPrompt != "project prompt" || !config.ShowStats {
+		t.Errorf("got Model %q, SystemPrompt %q and ShowStats %v", config.Model, config.SystemPrompt, config.ShowStats)
+	}
+	wantEmbed := []string{filepath.Join("home", "notes.md"), filepath.Join("project", "README.md")}
+	if !reflect.DeepEqual(config.Embed, wantEmbed) {
+		t.Errorf("got Embed %v, want %v", config.Embed, wantEmbed)
+	}
+	if config.Include != nil {
+		t.Errorf("Include should not be kept, got %v", config.Include)
+	}
+	wantFiles := []string{"home/config.yaml", "home/base.toml", "project/.gpt.json"}
+	for i := range wantFiles {
+		wantFiles[i] = filepath.Join(dir, filepath.FromSlash(wantFiles[i]))
+	}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("got files %v, want %v", files, wantFiles)
+	}
+}
+
+func TestLoadLayersIncludes(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{
+			name: "diamond",
+			files: map[string]string{
+				"config.toml": "Include = [\"a.toml\", \"b.toml\"]\n",
+				"a.toml":      "Include = [\"common.toml\"]\n",
+				"b.toml":      "Include = [\"common.toml\"]\n",
+				"common.toml": "Model = \"common\"\n",
+			},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"config.toml": "Include = [\"a.toml\"]\n",
+				"a.toml":      "Include = [\"config.toml\"]\n",
+			},
+			err: "includes itself",
+		},
+		{
+			name:  "missing",
+			files: map[string]string{"config.toml": "Include = [\"missing.toml\"]\n"},
+			err:   "no such file",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range test.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+			_, _, err := LoadLayers(filepath.Join(dir, "config.toml"), dir)
+			if test.err == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+				t.Errorf("got error %v, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestProjectLayerRestrictions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"allowed fields", "Model = \"gpt-4o\"\nEmbed = [\"docs/a.md\"]\n", ""},
+		{"refused field", "FixCommand = \"rm -rf /\"\n", "not FixCommand"},
+		{"refused included field", "Include = [\"extra.toml\"]\n", "not Webhooks"},
+		{"embed outside", "Embed = [\"../secret\"]\n", "files of their directory"},
+		{"absolute embed", "Embed = [\"/etc/passwd\"]\n", "files of their directory"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "config.toml"), "")
+			writeFile(t, filepath.Join(dir, "project", ".gpt.toml"), test.content)
+			writeFile(t, filepath.Join(dir, "project", "extra.toml"), "[[Webhooks]]\nURL = \"https://example.com\"\n")
+			_, _, err := LoadLayers(filepath.Join(dir, "config.toml"), filepath.Join(dir, "project"))
+			if test.err == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+				t.Errorf("got error %v, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestProjectLayerSymlinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		embed  string
+		target string
+		err    bool
+	}{
+		{"file outside", "notes", "secret", true},
+		{"directory outside", "docs/secret", ".", true},
+		{"missing file in a directory outside", "docs/missing", ".", true},
+		{"file inside", "notes", "project/README.md", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "config.toml"), "")
+			writeFile(t, filepath.Join(dir, "secret"), "key")
+			writeFile(t, filepath.Join(dir, "project", "README.md"), "readme")
+			writeFile(t, filepath.Join(dir, "project", ".gpt.toml"), "Embed = [\""+test.embed+"\"]\n")
+			link := filepath.Join(dir, "project", filepath.Dir(test.embed))
+			if filepath.Dir(test.embed) == "." {
+				link = filepath.Join(dir, "project", test.embed)
+			}
+			if err := os.Symlink(filepath.Join(dir, test.target), link); err != nil {
+				t.Skip("symlinks are not supported:", err)
+			}
+			_, _, err := LoadLayers(filepath.Join(dir, "config.toml"), filepath.Join(dir, "project"))
+			if test.err != (err != nil) {
+				t.Errorf("got error %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package i18n
+
+var spanish = map[string]string{
+	// REPL
+	"GPT Client in Go. Use `%shelp` for help.\n": "Cliente GPT en Go. Escribe `%shelp` para ver la ayuda.\n",
+	"Goodbye!":                    "¡Hasta luego!",
+	" [y/N] ":                     " [s/N] ",
+	"Error: missing command name": "Error: falta el nombre del comando",
+	"Error: `%s%s` is not a command, did you mean `%s%s`?\n":                 "Error: `%s%s` no es un comando, ¿quisiste decir `%s%s`?\n",
+	"Error: `%s%s` is not a command, see `%shelp`\n":                         "Error: `%s%s` no es un comando, consulta `%shelp`\n",
+	"Unknown config field: %s, did you mean `%s`?\n":                         "Campo de configuración desconocido: %s, ¿quisiste decir `%s`?\n",
+	"Unknown config field: %s\n":                                             "Campo de configuración desconocido: %s\n",
+	"Config updated for this session: %s = %s, use `--persist` to save it\n": "Configuración cambiada para esta sesión: %s = %s, usa `--persist` para guardarla\n",
+	"Config saved: %s = %s\n":                                                "Configuración guardada: %s = %s\n",
+	"Nothing to copy!":                                                       "¡Nada que copiar!",
+	"Assistant:":                                                             "Asistente:",
+	"Tool call:":                                                             "Llamada a herramienta:",
+	"Tool result:":                                                           "Resultado de la herramienta:",
+	"LLM response copied to clipboard":                                       "Respuesta copiada al portapapeles",
+
+	// Confirmations
+	"Add it to the context?": "¿Añadirlo al contexto?",
+	"Run this command?":      "¿Ejecutar este comando?",
+	"Follow-up question about the output (Enter to skip): ": "Pregunta sobre la salida (Enter para omitir): ",
+	"Apply the changes?":                                     "¿Aplicar los cambios?",
+	"Stage changes? [a]ll, [p]ick hunks, [N]o ":              "¿Preparar los cambios? [a] todos, [p] elegir fragmentos, [N]o ",
+	"[c]ommit, [e]dit then commit, [N]o ":                    "[c] hacer commit, [e] editar y hacer commit, [N]o ",
+	"Discard the changes?":                                   "¿Descartar los cambios?",
+	"Attach `%s`?":                                           "¿Adjuntar `%s`?",
+	"Reset every field of the config to its default?":        "¿Restablecer todos los campos de la configuración a su valor por defecto?",
+	"`%s` is bigger than %d bytes, embed a summary instead?": "`%s` ocupa más de %d bytes, ¿incluir un resumen en su lugar?",
+	"Queue the message to send it later?":                    "¿Poner el mensaje en cola para enviarlo más tarde?",
+	"Send \"%s\"?":                                           "¿Enviar \"%s\"?",
+	"You are back online, replay the %d queued messages?":    "Vuelves a estar en línea, ¿reenviar los %d mensajes en cola?",
+	"[c]opy, [p]ost with gh, [N]othing ":                     "[c] copiar, [p] publicar con gh, [N]ada ",
+	"Write `%s`?":                                            "¿Escribir `%s`?",
+	"Overwrite `%s`?":                                        "¿Sobrescribir `%s`?",
+	"Run `go test`?":                                         "¿Ejecutar `go test`?",
+	"Ask for a fix?":                                         "¿Pedir una corrección?",
+	"Run this program?":                                      "¿Ejecutar este programa?",
+	"Keep which alternative? [1-%d, Enter to discard all] ":  "¿Qué alternativa conservar? [1-%d, Enter para descartarlas todas] ",
+	"Recording, press Enter to stop...":                      "Grabando, pulsa Enter para parar...",
+	"Create `%s` (%d bytes)?":                                "¿Crear `%s` (%d bytes)?",
+	"Overwrite `%s` (%d bytes)?":                             "¿Sobrescribir `%s` (%d bytes)?",
+
+	// Help
+	"Help:":     "Ayuda:",
+	"Usage:":    "Uso:",
+	"Examples:": "Ejemplos:",
+	"Use `%shelp <group>` or `%shelp <command>` for details\n":           "Escribe `%shelp <grupo>` o `%shelp <comando>` para ver los detalles\n",
+	"Error: `%s` is neither a group nor a command, did you mean `%s`?\n": "Error: `%s` no es un grupo ni un comando, ¿quisiste decir `%s`?\n",
+	"Error: `%s` is neither a group (%s) nor a command\n":                "Error: `%s` no es un grupo (%s) ni un comando\n",
+	"Session":  "Sesión",
+	"Context":  "Contexto",
+	"Output":   "Salida",
+	"Settings": "Ajustes",
+	"Tools":    "Herramientas",
+	"Other":    "Otros",
+
+	// Commands
+	"Manipulate the system prompt": "Gestionar el prompt del sistema",
+	"Embed a file (or file:120-200, file.go#Func), a directory, `-` (paste) or `clipboard`, or manage embedded content": "Incluir un archivo (o archivo:120-200, archivo.go#Func), un directorio, `-` (pegar) o `clipboard`, o gestionar el contenido incluido",
+	"Save the history to <path> (JSON format)":                                                      "Guardar el historial en <path> (formato JSON)",
+	"Load the history from <path> (JSON format)":                                                    "Cargar el historial desde <path> (formato JSON)",
+	"Send <prompt> to every model of `CompareModels` in parallel":                                   "Enviar <prompt> a todos los modelos de `CompareModels` en paralelo",
+	"Show the last (or <n>th most recent) response again":                                           "Volver a mostrar la última (o la <n>-ésima más reciente) respuesta",
+	"List the last <n> messages of the conversation with their numbers":                             "Listar los últimos <n> mensajes de la conversación con sus números",
+	"Search the conversation for lines matching <regex>":                                            "Buscar en la conversación las líneas que coinciden con <regex>",
+	"Render message <n> of the conversation as Markdown":                                            "Mostrar el mensaje <n> de la conversación como Markdown",
+	"Export the session, prompts and config as a bundle to <path>":                                  "Exportar la sesión, los prompts y la configuración en el paquete <path>",
+	"Import a session bundle from <path>":                                                           "Importar un paquete de sesión desde <path>",
+	"Generate an image from <prompt>":                                                               "Generar una imagen a partir de <prompt>",
+	"Edit an image following \"<instructions>\" (optionally --mask <mask>), or create a variation":  "Editar una imagen según \"<instructions>\" (opcionalmente con --mask <mask>), o crear una variante",
+	"Transcribe an audio file, add it as context with --context or send it with --send":             "Transcribir un archivo de audio, añadirlo al contexto con --context o enviarlo con --send",
+	"Toggle voice mode, spoken prompts and responses":                                               "Activar o desactivar el modo de voz, prompts y respuestas habladas",
+	"Change a request parameter for this session, without saving it":                                "Cambiar un parámetro de las peticiones para esta sesión, sin guardarlo",
+	"Generate n responses to \"<prompt>\" and pick the one to keep":                                 "Generar n respuestas a \"<prompt>\" y elegir la que se conserva",
+	"Print a structured summary, kept out of the conversation":                                      "Mostrar un resumen estructurado, fuera de la conversación",
+	"Translate <text | file | clipboard>, to TranslateTo or the locale language by default":         "Traducir <text | file | clipboard>, a TranslateTo o al idioma de la configuración regional por defecto",
+	"Explain a file or a selection such as file:10-40, kept out of the conversation":                "Explicar un archivo o una selección como archivo:10-40, fuera de la conversación",
+	"Stage changes, generate a commit message, edit it and commit":                                  "Preparar los cambios, generar un mensaje de commit, editarlo y hacer el commit",
+	"Generate a pull request title and description for the current branch":                          "Generar el título y la descripción de una pull request para la rama actual",
+	"Generate table-driven tests for the exported functions of a Go file":                           "Generar tests con tablas para las funciones exportadas de un archivo Go",
+	"Run FixCommand, or the given command, and fix its failures until it succeeds":                  "Ejecutar FixCommand, o el comando dado, y corregir sus errores hasta que funcione",
+	"Turn \"<what you want>\" into a shell command and run it once confirmed":                       "Convertir \"<what you want>\" en un comando de shell y ejecutarlo tras confirmarlo",
+	"Add the last lines of the terminal (tmux pane, iTerm2, WezTerm or kitty) to the context":       "Añadir las últimas líneas del terminal (panel de tmux, iTerm2, WezTerm o kitty) al contexto",
+	"Toggle clipboard watch mode, new clipboard content is offered as context":                      "Activar o desactivar la vigilancia del portapapeles, su nuevo contenido se ofrece como contexto",
+	"Insert the clipboard in the next message, fenced if it looks like code (or press Ctrl-V)":      "Insertar el portapapeles en el próximo mensaje, como bloque de código si lo parece (o pulsa Ctrl-V)",
+	"List the messages queued while offline, send them or drop them":                                "Listar los mensajes en cola sin conexión, enviarlos o descartarlos",
+	"Check the API key, the configured models and the latency":                                      "Comprobar la clave de la API, los modelos configurados y la latencia",
+	"Preview an image in the terminal, or open it":                                                  "Mostrar una imagen en el terminal, o abrirla",
+	"Copy the last LLM response to clipboard, or the conversation, as Markdown, plain text or HTML": "Copiar la última respuesta al portapapeles, o la conversación, en Markdown, texto plano o HTML",
+	"Append what is shown to <path>, Markdown for .md files, or stop with `off`":                    "Añadir lo que se muestra a <path>, en Markdown para los archivos .md, o parar con `off`",
+	"Show / edit the config, for the session unless --persist is given":                             "Mostrar / editar la configuración, para la sesión salvo con --persist",
+	"Show spending against the budgets, or lift the hard stop":                                      "Mostrar el gasto frente a los presupuestos, o levantar el bloqueo",
+	"Show the pricing table, or the price of <model>":                                               "Mostrar la tabla de precios, o el precio de <model>",
+	"Store a fact that is added to every system prompt":                                             "Guardar un dato que se añade a cada prompt del sistema",
+	"List the stored facts, or forget one by number (or `all`)":                                     "Listar los datos guardados, u olvidar uno por su número (o `all`)",
+	"Show the session title, or rename the session":                                                 "Mostrar el título de la sesión, o renombrarla",
+	"List the archived sessions":                                                                    "Listar las sesiones archivadas",
+	"Resume the most recently active session":                                                       "Retomar la última sesión activa",
+	"Search archived conversations, then load or quote a result":                                    "Buscar en las conversaciones archivadas, y cargar o citar un resultado",
+	"Rate the last response, with an optional comment":                                              "Valorar la última respuesta, con un comentario opcional",
+	"Show session statistics, or export them as CSV to <path>":                                      "Mostrar las estadísticas de la sesión, o exportarlas como CSV a <path>",
+	"Display this help, or the help of a group or a command":                                        "Mostrar esta ayuda, o la ayuda de un grupo o de un comando",
+	"Exit the REPL": "Salir del REPL",
+}
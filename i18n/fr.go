@@ -0,0 +1,107 @@
+package i18n
+
+var french = map[string]string{
+	// REPL
+	"GPT Client in Go. Use `%shelp` for help.\n": "Client GPT en Go. Tapez `%shelp` pour l'aide.\n",
+	"Goodbye!":                    "Au revoir !",
+	" [y/N] ":                     " [o/N] ",
+	"Error: missing command name": "Erreur : nom de commande manquant",
+	"Error: `%s%s` is not a command, did you mean `%s%s`?\n":                 "Erreur : `%s%s` n'est pas une commande, vouliez-vous dire `%s%s` ?\n",
+	"Error: `%s%s` is not a command, see `%shelp`\n":                         "Erreur : `%s%s` n'est pas une commande, voir `%shelp`\n",
+	"Unknown config field: %s, did you mean `%s`?\n":                         "Champ de configuration inconnu : %s, vouliez-vous dire `%s` ?\n",
+	"Unknown config field: %s\n":                                             "Champ de configuration inconnu : %s\n",
+	"Config updated for this session: %s = %s, use `--persist` to save it\n": "Configuration modifiée pour cette session : %s = %s, utilisez `--persist` pour l'enregistrer\n",
+	"Config saved: %s = %s\n":                                                "Configuration enregistrée : %s = %s\n",
+	"Nothing to copy!":                                                       "Rien à copier !",
+	"Assistant:":                                                             "Assistant :",
+	"Tool call:":                                                             "Appel d'outil :",
+	"Tool result:":                                                           "Résultat de l'outil :",
+	"LLM response copied to clipboard":                                       "Réponse copiée dans le presse-papiers",
+
+	// Confirmations
+	"Add it to the context?": "L'ajouter au contexte ?",
+	"Run this command?":      "Lancer cette commande ?",
+	"Follow-up question about the output (Enter to skip): ": "Question sur la sortie (Entrée pour passer) : ",
+	"Apply the changes?":                                     "Appliquer les changements ?",
+	"Stage changes? [a]ll, [p]ick hunks, [N]o ":              "Indexer les changements ? [a] tout, [p] choisir des hunks, [N]on ",
+	"[c]ommit, [e]dit then commit, [N]o ":                    "[c] committer, [e] modifier puis committer, [N]on ",
+	"Discard the changes?":                                   "Abandonner les changements ?",
+	"Attach `%s`?":                                           "Joindre `%s` ?",
+	"Reset every field of the config to its default?":        "Remettre tous les champs de la configuration à leur valeur par défaut ?",
+	"`%s` is bigger than %d bytes, embed a summary instead?": "`%s` fait plus de %d octets, inclure un résumé à la place ?",
+	"Queue the message to send it later?":                    "Mettre le message en attente pour l'envoyer plus tard ?",
+	"Send \"%s\"?":                                           "Envoyer \"%s\" ?",
+	"You are back online, replay the %d queued messages?":    "Vous êtes de nouveau en ligne, renvoyer les %d messages en attente ?",
+	"[c]opy, [p]ost with gh, [N]othing ":                     "[c] copier, [p] publier avec gh, [N] rien ",
+	"Write `%s`?":                                            "Écrire `%s` ?",
+	"Overwrite `%s`?":                                        "Écraser `%s` ?",
+	"Run `go test`?":                                         "Lancer `go test` ?",
+	"Ask for a fix?":                                         "Demander une correction ?",
+	"Run this program?":                                      "Lancer ce programme ?",
+	"Keep which alternative? [1-%d, Enter to discard all] ":  "Garder quelle variante ? [1-%d, Entrée pour tout abandonner] ",
+	"Recording, press Enter to stop...":                      "Enregistrement, appuyez sur Entrée pour arrêter...",
+	"Create `%s` (%d bytes)?":                                "Créer `%s` (%d octets) ?",
+	"Overwrite `%s` (%d bytes)?":                             "Écraser `%s` (%d octets) ?",
+
+	// Help
+	"Help:":     "Aide :",
+	"Usage:":    "Utilisation :",
+	"Examples:": "Exemples :",
+	"Use `%shelp <group>` or `%shelp <command>` for details\n":           "Tapez `%shelp <groupe>` ou `%shelp <commande>` pour les détails\n",
+	"Error: `%s` is neither a group nor a command, did you mean `%s`?\n": "Erreur : `%s` n'est ni un groupe ni une commande, vouliez-vous dire `%s` ?\n",
+	"Error: `%s` is neither a group (%s) nor a command\n":                "Erreur : `%s` n'est ni un groupe (%s) ni une commande\n",
+	"Session":  "Session",
+	"Context":  "Contexte",
+	"Output":   "Affichage",
+	"Settings": "Réglages",
+	"Tools":    "Outils",
+	"Other":    "Autres",
+
+	// Commands
+	"Manipulate the system prompt": "Gérer le prompt système",
+	"Embed a file (or file:120-200, file.go#Func), a directory, `-` (paste) or `clipboard`, or manage embedded content": "Intégrer un fichier (ou fichier:120-200, fichier.go#Func), un dossier, `-` (collage) ou `clipboard`, ou gérer le contenu intégré",
+	"Save the history to <path> (JSON format)":                                                      "Enregistrer l'historique dans <path> (format JSON)",
+	"Load the history from <path> (JSON format)":                                                    "Charger l'historique depuis <path> (format JSON)",
+	"Send <prompt> to every model of `CompareModels` in parallel":                                   "Envoyer <prompt> à tous les modèles de `CompareModels` en parallèle",
+	"Show the last (or <n>th most recent) response again":                                           "Réafficher la dernière (ou la <n>-ième plus récente) réponse",
+	"List the last <n> messages of the conversation with their numbers":                             "Lister les <n> derniers messages de la conversation avec leurs numéros",
+	"Search the conversation for lines matching <regex>":                                            "Chercher dans la conversation les lignes correspondant à <regex>",
+	"Render message <n> of the conversation as Markdown":                                            "Afficher le message <n> de la conversation en Markdown",
+	"Export the session, prompts and config as a bundle to <path>":                                  "Exporter la session, les prompts et la configuration dans l'archive <path>",
+	"Import a session bundle from <path>":                                                           "Importer une archive de session depuis <path>",
+	"Generate an image from <prompt>":                                                               "Générer une image à partir de <prompt>",
+	"Edit an image following \"<instructions>\" (optionally --mask <mask>), or create a variation":  "Modifier une image selon \"<instructions>\" (avec --mask <mask> en option), ou en créer une variante",
+	"Transcribe an audio file, add it as context with --context or send it with --send":             "Transcrire un fichier audio, l'ajouter au contexte avec --context ou l'envoyer avec --send",
+	"Toggle voice mode, spoken prompts and responses":                                               "Activer ou désactiver le mode vocal, prompts et réponses parlés",
+	"Change a request parameter for this session, without saving it":                                "Changer un paramètre des requêtes pour cette session, sans l'enregistrer",
+	"Generate n responses to \"<prompt>\" and pick the one to keep":                                 "Générer n réponses à \"<prompt>\" et choisir celle à garder",
+	"Print a structured summary, kept out of the conversation":                                      "Afficher un résumé structuré, hors de la conversation",
+	"Translate <text | file | clipboard>, to TranslateTo or the locale language by default":         "Traduire <text | file | clipboard>, vers TranslateTo ou la langue de la locale par défaut",
+	"Explain a file or a selection such as file:10-40, kept out of the conversation":                "Expliquer un fichier ou une sélection comme fichier:10-40, hors de la conversation",
+	"Stage changes, generate a commit message, edit it and commit":                                  "Indexer les changements, générer un message de commit, le modifier et committer",
+	"Generate a pull request title and description for the current branch":                          "Générer le titre et la description d'une pull request pour la branche courante",
+	"Generate table-driven tests for the exported functions of a Go file":                           "Générer des tests par table pour les fonctions exportées d'un fichier Go",
+	"Run FixCommand, or the given command, and fix its failures until it succeeds":                  "Lancer FixCommand, ou la commande donnée, et corriger ses erreurs jusqu'à ce qu'elle réussisse",
+	"Turn \"<what you want>\" into a shell command and run it once confirmed":                       "Transformer \"<what you want>\" en commande shell et la lancer après confirmation",
+	"Add the last lines of the terminal (tmux pane, iTerm2, WezTerm or kitty) to the context":       "Ajouter les dernières lignes du terminal (panneau tmux, iTerm2, WezTerm ou kitty) au contexte",
+	"Toggle clipboard watch mode, new clipboard content is offered as context":                      "Activer ou désactiver la surveillance du presse-papiers, son nouveau contenu est proposé comme contexte",
+	"Insert the clipboard in the next message, fenced if it looks like code (or press Ctrl-V)":      "Insérer le presse-papiers dans le prochain message, en bloc de code s'il ressemble à du code (ou Ctrl-V)",
+	"List the messages queued while offline, send them or drop them":                                "Lister les messages mis en attente hors ligne, les envoyer ou les supprimer",
+	"Check the API key, the configured models and the latency":                                      "Vérifier la clé d'API, les modèles configurés et la latence",
+	"Preview an image in the terminal, or open it":                                                  "Afficher une image dans le terminal, ou l'ouvrir",
+	"Copy the last LLM response to clipboard, or the conversation, as Markdown, plain text or HTML": "Copier la dernière réponse dans le presse-papiers, ou la conversation, en Markdown, texte brut ou HTML",
+	"Append what is shown to <path>, Markdown for .md files, or stop with `off`":                    "Ajouter ce qui est affiché à <path>, en Markdown pour les fichiers .md, ou arrêter avec `off`",
+	"Show / edit the config, for the session unless --persist is given":                             "Afficher / modifier la configuration, pour la session sauf avec --persist",
+	"Show spending against the budgets, or lift the hard stop":                                      "Afficher les dépenses par rapport aux budgets, ou lever le blocage",
+	"Show the pricing table, or the price of <model>":                                               "Afficher la grille tarifaire, ou le prix de <model>",
+	"Store a fact that is added to every system prompt":                                             "Mémoriser un fait ajouté à chaque prompt système",
+	"List the stored facts, or forget one by number (or `all`)":                                     "Lister les faits mémorisés, ou en oublier un par son numéro (ou `all`)",
+	"Show the session title, or rename the session":                                                 "Afficher le titre de la session, ou la renommer",
+	"List the archived sessions":                                                                    "Lister les sessions archivées",
+	"Resume the most recently active session":                                                       "Reprendre la dernière session active",
+	"Search archived conversations, then load or quote a result":                                    "Chercher dans les conversations archivées, puis charger ou citer un résultat",
+	"Rate the last response, with an optional comment":                                              "Noter la dernière réponse, avec un commentaire optionnel",
+	"Show session statistics, or export them as CSV to <path>":                                      "Afficher les statistiques de la session, ou les exporter en CSV dans <path>",
+	"Display this help, or the help of a group or a command":                                        "Afficher cette aide, ou l'aide d'un groupe ou d'une commande",
+	"Exit the REPL": "Quitter le REPL",
+}
@@ -0,0 +1,87 @@
+// Package i18n translates the messages of the REPL. The English messages are
+// the keys of the catalogs, and messages missing from a catalog are shown in
+// English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Values of Language
+const (
+	ENGLISH = "en"
+	FRENCH  = "fr"
+	SPANISH = "es"
+	// The language of the locale, e.g. LANG=fr_FR.UTF-8
+	AUTO = "auto"
+)
+
+var catalogs = map[string]map[string]string{
+	FRENCH:  french,
+	SPANISH: spanish,
+}
+
+// Answers accepted by confirmations, besides `y` and `yes`
+var yes = map[string][]string{
+	FRENCH:  {"o", "oui"},
+	SPANISH: {"s", "si", "sí"},
+}
+
+var current = ENGLISH
+
+// Languages with a catalog, English included
+func Languages() []string {
+	return []string{ENGLISH, FRENCH, SPANISH}
+}
+
+// Sets the language of the messages, English when empty. Languages without a
+// catalog are refused and English is used instead.
+func SetLanguage(language string) error {
+	language = strings.ToLower(language)
+	if language == AUTO {
+		language = localeLanguage()
+	}
+	// `fr_FR.UTF-8` or `es-MX` use the catalog of the language
+	if i := strings.IndexAny(language, "_-."); i != -1 {
+		language = language[:i]
+	}
+	if language == "" {
+		language = ENGLISH
+	}
+	current = ENGLISH
+	if !slices.Contains(Languages(), language) {
+		return fmt.Errorf("no translations for `%s`, expected one of %s", language, strings.Join(Languages(), ", "))
+	}
+	current = language
+	return nil
+}
+
+func localeLanguage() string {
+	for _, variable := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(variable); locale != "" && locale != "C" && locale != "POSIX" {
+			return locale
+		}
+	}
+	return ENGLISH
+}
+
+// Translates message, and formats it with args like fmt.Sprintf when there
+// are some
+func T(message string, args ...any) string {
+	if translated, ok := catalogs[current][message]; ok {
+		message = translated
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Whether answer means yes, in English or in the current language
+func IsYes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes" || slices.Contains(yes[current], answer)
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"strings"
+
+	"gpt/config"
+)
+
+var DefaultPricing = map[string]config.ModelPrice{
+	"gpt-4o":        {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":   {Input: 0.15, Output: 0.60},
+	"gpt-4.1":       {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini":  {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano":  {Input: 0.10, Output: 0.40},
+	"gpt-4-turbo":   {Input: 10.00, Output: 30.00},
+	"gpt-3.5-turbo": {Input: 0.50, Output: 1.50},
+	"o1":            {Input: 15.00, Output: 60.00},
+	"o3-mini":       {Input: 1.10, Output: 4.40},
+}
+
+// Effective prices: the built-in table extended / overridden by the config
+var Prices = map[string]config.ModelPrice{}
+
+func LoadPricing(overrides map[string]config.ModelPrice) {
+	Prices = map[string]config.ModelPrice{}
+	for model, price := range DefaultPricing {
+		Prices[model] = price
+	}
+	for model, price := range overrides {
+		Prices[model] = price
+	}
+}
+
+// Dated snapshots (e.g. `gpt-4o-2024-08-06`) use the price of the longest
+// matching model name
+func LookupPrice(model string) (config.ModelPrice, bool) {
+	if price, ok := Prices[model]; ok {
+		return price, true
+	}
+	best := ""
+	for name := range Prices {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return config.ModelPrice{}, false
+	}
+	return Prices[best], true
+}
+
+func ComputeCost(model string, promptTokens, completionTokens int) float64 {
+	price, ok := LookupPrice(model)
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*price.Input + float64(completionTokens)*price.Output) / 1_000_000
+}
@@ -0,0 +1,190 @@
+// Package provider creates the clients of the OpenAI API, with rate limits
+// and retries, and prices their requests.
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"gpt/config"
+)
+
+const (
+	// Requests made at the same time by batch operations (compare, review,
+	// indexing) when `Concurrency` is not set
+	DEFAULT_CONCURRENCY = 4
+	// Attempts after a 429 response before giving up
+	MAX_RATE_LIMIT_RETRIES = 5
+)
+
+func Concurrency(config config.Config) int {
+	if config.Concurrency <= 0 {
+		return DEFAULT_CONCURRENCY
+	}
+	return config.Concurrency
+}
+
+// Calls fn for 0..n-1 with at most `workers` calls at a time
+func RunPool(workers int, n int, fn func(i int)) {
+	queue := make(chan int)
+	wg := sync.WaitGroup{}
+	for range min(workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// Retries are traced along with the requests of the chat package
+var tracer = otel.Tracer("go-gpt")
+
+type tokenUse struct {
+	Time   time.Time
+	Tokens int
+}
+
+// Keeps the requests and tokens of the last minute under the limits, 0
+// disables a limit
+type rateLimiter struct {
+	mu                sync.Mutex
+	requestsPerMinute int
+	tokensPerMinute   int
+	window            []tokenUse
+}
+
+// Blocks until a request of the given size fits in the limits
+func (l *rateLimiter) wait(tokens int) {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		for len(l.window) > 0 && now.Sub(l.window[0].Time) >= time.Minute {
+			l.window = l.window[1:]
+		}
+		used := 0
+		for _, use := range l.window {
+			used += use.Tokens
+		}
+		requestsOk := l.requestsPerMinute == 0 || len(l.window) < l.requestsPerMinute
+		// A request bigger than the limit is sent alone rather than never
+		tokensOk := l.tokensPerMinute == 0 || len(l.window) == 0 || used+tokens <= l.tokensPerMinute
+		if requestsOk && tokensOk {
+			l.window = append(l.window, tokenUse{Time: now, Tokens: tokens})
+			l.mu.Unlock()
+			return
+		}
+		delay := time.Minute - now.Sub(l.window[0].Time)
+		l.mu.Unlock()
+		time.Sleep(delay)
+	}
+}
+
+// Applies the rate limits to every API request, and retries throttled
+// requests after the delay given by the API
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The size of the body is a good enough estimate of the prompt tokens
+	tokens := int(req.ContentLength / 4)
+	canRetry := req.Body == nil || req.GetBody != nil
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+		t.limiter.wait(tokens)
+		resp, err := t.roundTrip(attemptReq, attempt)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || !canRetry || attempt == MAX_RATE_LIMIT_RETRIES {
+			return resp, err
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		// Retrying doesn't help once the quota is exhausted
+		if bytes.Contains(body, []byte("insufficient_quota")) {
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			return resp, nil
+		}
+		delay := retryDelay(resp.Header, attempt)
+		fmt.Printf("Rate limited, retrying in %s\n", delay.Round(time.Second))
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// Retries get their own span, as children of the span of the request
+func (t *rateLimitedTransport) roundTrip(req *http.Request, attempt int) (*http.Response, error) {
+	if attempt == 0 {
+		return t.base.RoundTrip(req)
+	}
+	_, span := tracer.Start(req.Context(), "retry", trace.WithAttributes(attribute.Int("attempt", attempt)))
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	return resp, err
+}
+
+// Honors the Retry-After headers, with an exponential backoff otherwise
+func retryDelay(header http.Header, attempt int) time.Duration {
+	if ms, err := strconv.Atoi(header.Get("Retry-After-Ms")); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	value := header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && time.Until(date) > 0 {
+		return time.Until(date)
+	}
+	return time.Second << attempt
+}
+
+// Every API client goes through the rate limits of the config
+func NewClient(config config.Config, apiKey string) *openai.Client {
+	clientConfig := openai.DefaultConfig(apiKey)
+	clientConfig.HTTPClient = &http.Client{
+		Transport: &rateLimitedTransport{
+			base: http.DefaultTransport,
+			limiter: &rateLimiter{
+				requestsPerMinute: config.RequestsPerMinute,
+				tokensPerMinute:   config.TokensPerMinute,
+			},
+		},
+	}
+	return openai.NewClientWithConfig(clientConfig)
+}
@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  http.Header
+		attempt int
+		want    time.Duration
+	}{
+		{"milliseconds", http.Header{"Retry-After-Ms": {"1500"}, "Retry-After": {"9"}}, 0, 1500 * time.Millisecond},
+		{"seconds", http.Header{"Retry-After": {"3"}}, 0, 3 * time.Second},
+		{"backoff", http.Header{}, 0, time.Second},
+		{"backoff after attempts", http.Header{}, 3, 8 * time.Second},
+		{"invalid header", http.Header{"Retry-After": {"soon"}}, 1, 2 * time.Second},
+		{"past date", http.Header{"Retry-After": {"Mon, 02 Jan 2006 15:04:05 GMT"}}, 0, time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := retryDelay(test.header, test.attempt); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	date := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	if got := retryDelay(http.Header{"Retry-After": {date}}, 0); got <= 8*time.Second || got > 10*time.Second {
+		t.Errorf("date, got %s", got)
+	}
+}
+
+func TestRateLimiterWindow(t *testing.T) {
+	old := time.Now().Add(-2 * time.Minute)
+	tests := []struct {
+		name    string
+		limiter *rateLimiter
+		tokens  int
+		wantLen int
+	}{
+		{"no limits", &rateLimiter{window: []tokenUse{{time.Now(), 1000}}}, 1000, 2},
+		{"old uses are dropped", &rateLimiter{requestsPerMinute: 1, window: []tokenUse{{old, 10}}}, 10, 1},
+		{"under the limits", &rateLimiter{requestsPerMinute: 2, tokensPerMinute: 100, window: []tokenUse{{time.Now(), 50}}}, 50, 2},
+		{"bigger than the limit", &rateLimiter{tokensPerMinute: 100}, 500, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			done := make(chan bool)
+			go func() {
+				test.limiter.wait(test.tokens)
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("wait blocked")
+			}
+			if len(test.limiter.window) != test.wantLen {
+				t.Errorf("got %d uses in the window, want %d", len(test.limiter.window), test.wantLen)
+			}
+		})
+	}
+}
+
+func TestRateLimiterBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter *rateLimiter
+	}{
+		{"requests", &rateLimiter{requestsPerMinute: 1, window: []tokenUse{{time.Now(), 0}}}},
+		{"tokens", &rateLimiter{tokensPerMinute: 100, window: []tokenUse{{time.Now(), 60}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			done := make(chan bool)
+			go func() {
+				test.limiter.wait(50)
+				close(done)
+			}()
+			select {
+			case <-done:
+				t.Error("wait should block until the window has room")
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestRateLimitedTransportRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		body         string
+		wantStatus   int
+		wantRequests int32
+	}{
+		{"retried", 2, "", http.StatusOK, 3},
+		{"too many retries", MAX_RATE_LIMIT_RETRIES + 1, "", http.StatusTooManyRequests, MAX_RATE_LIMIT_RETRIES + 1},
+		{"insufficient quota", 1, `{"error": {"code": "insufficient_quota"}}`, http.StatusTooManyRequests, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != "prompt" {
+					t.Errorf("attempt %d got body %q", requests.Load(), body)
+				}
+				if requests.Add(1) <= test.failures {
+					w.Header().Set("Retry-After-Ms", "1")
+					w.WriteHeader(http.StatusTooManyRequests)
+					io.WriteString(w, test.body)
+					return
+				}
+				io.WriteString(w, "ok")
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &rateLimitedTransport{base: http.DefaultTransport, limiter: &rateLimiter{}}}
+			resp, err := client.Post(server.URL, "text/plain", strings.NewReader("prompt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != test.wantStatus || requests.Load() != test.wantRequests {
+				t.Errorf("got status %d after %d requests, want %d after %d", resp.StatusCode, requests.Load(), test.wantStatus, test.wantRequests)
+			}
+			if test.body != "" && string(body) != test.body {
+				t.Errorf("the body of the response was lost, got %q", body)
+			}
+		})
+	}
+}
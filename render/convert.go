@@ -0,0 +1,142 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// GitHub flavored, like the Markdown the models write
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// Converts Markdown to an HTML fragment
+func HTML(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Strips the Markdown syntax of source: emphasis and headings become plain
+// text, links are followed by their URL, list items keep their bullet or
+// number, code blocks their content and table cells are separated by tabs
+func PlainText(source string) string {
+	src := []byte(source)
+	doc := markdown.Parser().Parse(text.NewReader(src))
+	var sb strings.Builder
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch n := n.(type) {
+		case *ast.Text:
+			if entering {
+				sb.Write(n.Segment.Value(src))
+				if n.SoftLineBreak() || n.HardLineBreak() {
+					sb.WriteString("\n")
+				}
+			}
+			return ast.WalkContinue, nil
+		case *ast.String:
+			if entering {
+				sb.Write(n.Value)
+			}
+			return ast.WalkContinue, nil
+		case *ast.AutoLink:
+			if entering {
+				sb.Write(n.Label(src))
+			}
+			return ast.WalkContinue, nil
+		case *ast.Link:
+			if !entering && !strings.HasSuffix(sb.String(), string(n.Destination)) {
+				fmt.Fprintf(&sb, " (%s)", n.Destination)
+			}
+			return ast.WalkContinue, nil
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			if entering {
+				lines := n.Lines()
+				for i := 0; i < lines.Len(); i++ {
+					segment := lines.At(i)
+					sb.Write(segment.Value(src))
+				}
+				endBlock(&sb, n)
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock, *ast.RawHTML, *ast.ThematicBreak:
+			return ast.WalkSkipChildren, nil
+		case *ast.ListItem:
+			if entering {
+				sb.WriteString(strings.Repeat("  ", listDepth(n)))
+				if list := n.Parent().(*ast.List); list.IsOrdered() {
+					fmt.Fprintf(&sb, "%d. ", list.Start+childIndex(n))
+				} else {
+					sb.WriteString("- ")
+				}
+			}
+			return ast.WalkContinue, nil
+		case *ast.List, *ast.Document:
+			if !entering && n.Parent() != nil && n.Parent().Kind() == ast.KindDocument {
+				sb.WriteString("\n")
+			}
+			return ast.WalkContinue, nil
+		case *east.TaskCheckBox:
+			if entering && n.IsChecked {
+				sb.WriteString("[x] ")
+			} else if entering {
+				sb.WriteString("[ ] ")
+			}
+			return ast.WalkContinue, nil
+		case *east.TableCell:
+			if !entering && n.NextSibling() != nil {
+				sb.WriteString("\t")
+			}
+			return ast.WalkContinue, nil
+		case *east.TableHeader, *east.TableRow:
+			if !entering {
+				sb.WriteString("\n")
+			}
+			return ast.WalkContinue, nil
+		}
+		if !entering && n.Type() == ast.TypeBlock {
+			endBlock(&sb, n)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(blankLines.ReplaceAllString(sb.String(), "\n\n"))
+}
+
+// Ends the line of a block, and leaves a blank line after the top-level ones
+func endBlock(sb *strings.Builder, n ast.Node) {
+	if !strings.HasSuffix(sb.String(), "\n") {
+		sb.WriteString("\n")
+	}
+	if n.Parent() != nil && n.Parent().Kind() == ast.KindDocument {
+		sb.WriteString("\n")
+	}
+}
+
+// Number of lists item is nested in, 0 for the items of a top-level list
+func listDepth(item ast.Node) int {
+	depth := -1
+	for n := item.Parent(); n != nil; n = n.Parent() {
+		if n.Kind() == ast.KindList {
+			depth++
+		}
+	}
+	return depth
+}
+
+func childIndex(n ast.Node) int {
+	i := 0
+	for sibling := n.PreviousSibling(); sibling != nil; sibling = sibling.PreviousSibling() {
+		i++
+	}
+	return i
+}
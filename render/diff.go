@@ -0,0 +1,259 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/chzyer/readline"
+	"github.com/mattn/go-runewidth"
+
+	"gpt/config"
+)
+
+// Values of DiffStyle
+const (
+	DIFF_UNIFIED      = "unified"
+	DIFF_SIDE_BY_SIDE = "side-by-side"
+)
+
+const (
+	DIFF_CONTEXT = 3
+	// Bigger changes are shown as a whole replacement, the table would be
+	// too big
+	MAX_DIFF_CELLS = 25_000_000
+	// Below this width side-by-side diffs fall back to unified diffs
+	MIN_SIDE_BY_SIDE_WIDTH = 100
+)
+
+var (
+	diffRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	diffAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	diffHunkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	diffHeaderStyle  = lipgloss.NewStyle().Bold(true)
+)
+
+type diffOp struct {
+	Kind byte // ' ', '-' or '+'
+	Line string
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// Line diff based on the longest common subsequence, after skipping the
+// common prefix and suffix
+func diffLines(a []string, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := []diffOp{}
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	x, y := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(x)*len(y) > MAX_DIFF_CELLS {
+		for _, line := range x {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range y {
+			ops = append(ops, diffOp{'+', line})
+		}
+	} else {
+		// lcs[i][j] is the length of the LCS of x[i:] and y[j:]
+		lcs := make([][]int32, len(x)+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, len(y)+1)
+		}
+		for i := len(x) - 1; i >= 0; i-- {
+			for j := len(y) - 1; j >= 0; j-- {
+				if x[i] == y[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(x) || j < len(y) {
+			switch {
+			case i < len(x) && j < len(y) && x[i] == y[j]:
+				ops = append(ops, diffOp{' ', x[i]})
+				i++
+				j++
+			// Removed lines come first, like git
+			case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+				ops = append(ops, diffOp{'-', x[i]})
+				i++
+			default:
+				ops = append(ops, diffOp{'+', y[j]})
+				j++
+			}
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+type diffHunk struct {
+	OldStart, NewStart int
+	Ops                []diffOp
+}
+
+// Groups the changes with DIFF_CONTEXT lines of context around them
+func diffHunks(ops []diffOp) []diffHunk {
+	hunks := []diffHunk{}
+	oldLine, newLine := 1, 1
+	var current *diffHunk
+	unchanged := 0
+	for i, op := range ops {
+		if op.Kind != ' ' {
+			if current == nil {
+				start := max(0, i-DIFF_CONTEXT)
+				hunks = append(hunks, diffHunk{OldStart: oldLine - (i - start), NewStart: newLine - (i - start)})
+				current = &hunks[len(hunks)-1]
+				current.Ops = append(current.Ops, ops[start:i]...)
+			}
+			unchanged = 0
+		} else if current != nil {
+			unchanged++
+			// Close the hunk unless another change comes soon
+			if unchanged > 2*DIFF_CONTEXT {
+				current.Ops = current.Ops[:len(current.Ops)-DIFF_CONTEXT]
+				current = nil
+			}
+		}
+		if current != nil {
+			current.Ops = append(current.Ops, op)
+		}
+		if op.Kind != '+' {
+			oldLine++
+		}
+		if op.Kind != '-' {
+			newLine++
+		}
+	}
+	if current != nil && unchanged > DIFF_CONTEXT {
+		current.Ops = current.Ops[:len(current.Ops)-(unchanged-DIFF_CONTEXT)]
+	}
+	return hunks
+}
+
+func hunkHeader(hunk diffHunk) string {
+	oldCount, newCount := 0, 0
+	for _, op := range hunk.Ops {
+		if op.Kind != '+' {
+			oldCount++
+		}
+		if op.Kind != '-' {
+			newCount++
+		}
+	}
+	// Like git, an empty side starts before the first line
+	oldStart, newStart := hunk.OldStart, hunk.NewStart
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount)
+}
+
+// Prints the changes from old to new content of a file, in the DiffStyle of
+// the config
+func Diff(config config.Config, path string, old string, new string) {
+	hunks := diffHunks(diffLines(splitLines(old), splitLines(new)))
+	fmt.Println(diffHeaderStyle.Render(fmt.Sprintf("--- %s", path)))
+	if len(hunks) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+	width := readline.GetScreenWidth()
+	if config.DiffStyle == DIFF_SIDE_BY_SIDE && width >= MIN_SIDE_BY_SIDE_WIDTH && !Accessible {
+		printSideBySide(hunks, width)
+		return
+	}
+	for _, hunk := range hunks {
+		fmt.Println(diffHunkStyle.Render(hunkHeader(hunk)))
+		for _, op := range hunk.Ops {
+			line := string(op.Kind) + op.Line
+			if Accessible {
+				line = accessibleDiffLine(op)
+			}
+			switch op.Kind {
+			case '-':
+				line = diffRemovedStyle.Render(line)
+			case '+':
+				line = diffAddedStyle.Render(line)
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+// Changes are announced in words rather than by a sign
+func accessibleDiffLine(op diffOp) string {
+	switch op.Kind {
+	case '-':
+		return "removed: " + op.Line
+	case '+':
+		return "added: " + op.Line
+	}
+	return "unchanged: " + op.Line
+}
+
+func printSideBySide(hunks []diffHunk, width int) {
+	column := (width - 3) / 2
+	cell := func(s string) string {
+		s = strings.ReplaceAll(s, "\t", "    ")
+		// Wide characters take two columns
+		if runewidth.StringWidth(s) > column {
+			return runewidth.Truncate(s, column, "…")
+		}
+		return runewidth.FillRight(s, column)
+	}
+	for _, hunk := range hunks {
+		fmt.Println(diffHunkStyle.Render(hunkHeader(hunk)))
+		ops := hunk.Ops
+		for len(ops) > 0 {
+			if ops[0].Kind == ' ' {
+				fmt.Printf("%s │ %s\n", cell(ops[0].Line), cell(ops[0].Line))
+				ops = ops[1:]
+				continue
+			}
+			// Pair the removed lines of a block with its added lines
+			removed, added := []string{}, []string{}
+			for len(ops) > 0 && ops[0].Kind == '-' {
+				removed = append(removed, ops[0].Line)
+				ops = ops[1:]
+			}
+			for len(ops) > 0 && ops[0].Kind == '+' {
+				added = append(added, ops[0].Line)
+				ops = ops[1:]
+			}
+			for i := range max(len(removed), len(added)) {
+				left, right := cell(""), cell("")
+				if i < len(removed) {
+					left = diffRemovedStyle.Render(cell(removed[i]))
+				}
+				if i < len(added) {
+					right = diffAddedStyle.Render(cell(added[i]))
+				}
+				fmt.Printf("%s │ %s\n", left, right)
+			}
+		}
+	}
+}
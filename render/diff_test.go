@@ -0,0 +1,134 @@
+package render
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"gpt/config"
+)
+
+// Returns what fn prints
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func numberedLines(from int, to int) string {
+	sb := strings.Builder{}
+	for i := from; i <= to; i++ {
+		sb.WriteString(strings.Repeat("x", i) + "\n")
+	}
+	return sb.String()
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{
+			name: "no changes",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "--- f\nNo changes\n",
+		},
+		{
+			name: "new file",
+			old:  "",
+			new:  "a\nb\n",
+			want: "--- f\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "deleted file",
+			old:  "a\n",
+			new:  "",
+			want: "--- f\n@@ -1,1 +0,0 @@\n-a\n",
+		},
+		{
+			name: "replaced line",
+			old:  "a\nb\nc\n",
+			new:  "a\nB\nc\n",
+			want: "--- f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "context",
+			old:  numberedLines(1, 10),
+			new:  strings.Replace(numberedLines(1, 10), "xxxxx\n", "five\n", 1),
+			want: "--- f\n@@ -2,7 +2,7 @@\n xx\n xxx\n xxxx\n-xxxxx\n+five\n xxxxxx\n xxxxxxx\n xxxxxxxx\n",
+		},
+		{
+			name: "separate hunks",
+			old:  numberedLines(1, 20),
+			new:  "one\n" + strings.TrimPrefix(numberedLines(1, 20), "x\n") + "twenty-one\n",
+			want: "--- f\n@@ -1,4 +1,4 @@\n-x\n+one\n xx\n xxx\n xxxx\n" +
+				"@@ -18,3 +18,4 @@\n " + strings.Repeat("x", 18) + "\n " + strings.Repeat("x", 19) + "\n " + strings.Repeat("x", 20) + "\n+twenty-one\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := captureStdout(t, func() {
+				Diff(config.Config{}, "f", test.old, test.new)
+			})
+			if got != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiffAccessible(t *testing.T) {
+	Accessible = true
+	defer func() { Accessible = false }()
+	got := captureStdout(t, func() {
+		Diff(config.Config{DiffStyle: DIFF_SIDE_BY_SIDE}, "f", "a\n", "b\n")
+	})
+	for _, line := range []string{accessibleDiffLine(diffOp{'-', "a"}), accessibleDiffLine(diffOp{'+', "b"})} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("missing %q in\n%s", line, got)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	a := splitLines("a\nb\nc\nd\n")
+	b := splitLines("a\nc\nb\nd\n")
+	ops := diffLines(a, b)
+	old, new := []string{}, []string{}
+	for _, op := range ops {
+		if op.Kind != '+' {
+			old = append(old, op.Line)
+		}
+		if op.Kind != '-' {
+			new = append(new, op.Line)
+		}
+	}
+	if strings.Join(old, "\n") != strings.Join(a, "\n") || strings.Join(new, "\n") != strings.Join(b, "\n") {
+		t.Errorf("the operations don't rebuild both sides: %+v", ops)
+	}
+	changes := 0
+	for _, op := range ops {
+		if op.Kind != ' ' {
+			changes++
+		}
+	}
+	if changes != 2 {
+		t.Errorf("got %d changes, want 2: %+v", changes, ops)
+	}
+}
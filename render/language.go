@@ -0,0 +1,42 @@
+package render
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Checked in order, the first match wins
+var languagePatterns = []struct {
+	Language string
+	Pattern  *regexp.Regexp
+}{
+	{"php", regexp.MustCompile(`<\?php`)},
+	{"html", regexp.MustCompile(`(?i)<(!doctype|html|head|body|div|span|p|a|script)[\s>]`)},
+	{"go", regexp.MustCompile(`(?m)^package \w+$|\bfunc (\(\w+ \*?\w+\) )?\w+\(.*\{|:= |\bfmt\.\w+\(`)},
+	{"rust", regexp.MustCompile(`\bfn \w+.*(->|\{)|\blet mut\b|\bimpl\b.*\{|println!\(`)},
+	{"cpp", regexp.MustCompile(`std::|#include <(iostream|vector|string)>|\btemplate\s*<`)},
+	{"c", regexp.MustCompile(`#include [<"]|\bint main\(|printf\(`)},
+	{"java", regexp.MustCompile(`\bpublic (static )?(class|void|final)\b|System\.out\.`)},
+	{"typescript", regexp.MustCompile(`\binterface \w+ \{|: (string|number|boolean)\b|\bexport type\b`)},
+	{"javascript", regexp.MustCompile(`\bfunction\b.*\(|=> |\bconsole\.\w+\(|\b(const|let) \w+ = |\brequire\(`)},
+	{"python", regexp.MustCompile(`(?m)^\s*(def \w+\(.*\):|class \w+.*:|from \S+ import |import \w+$|if __name__)|\bprint\(|\bself\.`)},
+	{"sql", regexp.MustCompile(`(?i)\b(select .+ from|insert into|create table|update \w+ set)\b`)},
+	{"bash", regexp.MustCompile(`(?m)^#!.*\b(ba|z)?sh\b|^\$ |\becho \$|\bfi$|\bdone$`)},
+	{"yaml", regexp.MustCompile(`(?m)^[\w-]+:( .*)?$`)},
+}
+
+// Guesses the language of a snippet for the info string of a code block,
+// returns "" when unsure
+func GuessLanguage(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return "json"
+	}
+	for _, p := range languagePatterns {
+		if p.Pattern.MatchString(text) {
+			return p.Language
+		}
+	}
+	return ""
+}
@@ -0,0 +1,26 @@
+// Package render prints Markdown, tables and diffs to the terminal.
+package render
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/glamour"
+
+	"gpt/config"
+)
+
+// Screen reader friendly output: no box drawing, separators or side-by-side
+// layouts, and changes described in words. Set from the Accessible field of
+// the config.
+var Accessible bool
+
+// Prints text rendered as Markdown with the configured theme, or as is in
+// accessible mode
+func Markdown(config config.Config, text string) {
+	out, err := glamour.Render(text, config.Theme)
+	if err != nil || Accessible {
+		fmt.Println(text)
+		return
+	}
+	fmt.Print(out)
+}
@@ -0,0 +1,45 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Columns are aligned on the display width of the cells, so that accented,
+// CJK or emoji text stays aligned
+func Table(headers []string, rows [][]string) {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = runewidth.StringWidth(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], runewidth.StringWidth(cell))
+		}
+	}
+
+	printRow := func(cells []string) {
+		sb := strings.Builder{}
+		for i, cell := range cells {
+			sb.WriteString("    ")
+			sb.WriteString(cell)
+			sb.WriteString(strings.Repeat(" ", widths[i]-runewidth.StringWidth(cell)))
+		}
+		fmt.Println(strings.TrimRight(sb.String(), " "))
+	}
+
+	printRow(headers)
+	// Screen readers would read the dashes
+	if !Accessible {
+		separators := make([]string, len(headers))
+		for i := range headers {
+			separators[i] = strings.Repeat("-", widths[i])
+		}
+		printRow(separators)
+	}
+	for _, row := range rows {
+		printRow(row)
+	}
+}
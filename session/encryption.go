@@ -0,0 +1,172 @@
+package session
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+
+	"gpt/config"
+)
+
+// Encrypted files are `magic | salt | nonce | AES-256-GCM ciphertext`
+var encryptionMagic = []byte("GPTENC1\n")
+
+const ENCRYPTION_SALT_SIZE = 16
+
+var (
+	// Secret from `EncryptionPassphrase` or `EncryptionKeyFile`, nil when
+	// encryption is disabled
+	encryptionSecret []byte
+	// Salt used for the files written by this process, so that the key is
+	// only derived once
+	encryptionSalt []byte
+	// Keys by secret and salt, a key of a previous secret is not reused. Held
+	// while deriving, so that workers reading the same files derive it once.
+	derivedKeys      = map[string][]byte{}
+	derivedKeysMutex sync.Mutex
+)
+
+func SetupEncryption(config config.Config) error {
+	switch {
+	case config.EncryptionKeyFile != "":
+		data, err := os.ReadFile(config.EncryptionKeyFile)
+		if err != nil {
+			return err
+		}
+		encryptionSecret = bytes.TrimSpace(data)
+	case config.EncryptionPassphrase != "":
+		encryptionSecret = []byte(config.EncryptionPassphrase)
+	default:
+		return nil
+	}
+	if len(encryptionSecret) == 0 {
+		return errors.New("empty encryption key")
+	}
+	encryptionSalt = make([]byte, ENCRYPTION_SALT_SIZE)
+	_, err := rand.Read(encryptionSalt)
+	return err
+}
+
+func deriveKey(salt []byte) ([]byte, error) {
+	derivedKeysMutex.Lock()
+	defer derivedKeysMutex.Unlock()
+	id := string(encryptionSecret) + "\x00" + string(salt)
+	if key, ok := derivedKeys[id]; ok {
+		return key, nil
+	}
+	key, err := scrypt.Key(encryptionSecret, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	derivedKeys[id] = key
+	return key, nil
+}
+
+func newGCM(salt []byte) (cipher.AEAD, error) {
+	key, err := deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encrypt(data []byte) ([]byte, error) {
+	gcm, err := newGCM(encryptionSalt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append([]byte{}, encryptionMagic...)
+	out = append(out, encryptionSalt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, data, encryptionMagic), nil
+}
+
+func decrypt(data []byte) ([]byte, error) {
+	data = data[len(encryptionMagic):]
+	if len(data) < ENCRYPTION_SALT_SIZE {
+		return nil, errors.New("truncated encrypted file")
+	}
+	salt, data := data[:ENCRYPTION_SALT_SIZE], data[ENCRYPTION_SALT_SIZE:]
+	gcm, err := newGCM(salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("truncated encrypted file")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, encryptionMagic)
+	if err != nil {
+		return nil, errors.New("wrong encryption key or corrupted file")
+	}
+	return plaintext, nil
+}
+
+// Whether the files containing conversation data are encrypted
+func Encrypted() bool {
+	return encryptionSecret != nil
+}
+
+// Writes a file containing conversation data, encrypted when enabled
+func WriteSecureFile(path string, data []byte) error {
+	if encryptionSecret != nil {
+		var err error
+		if data, err = encrypt(data); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Appends to a file containing conversation data. Encrypted files are
+// rewritten as a whole, since they can't be appended to.
+func AppendSecureFile(path string, data []byte) error {
+	if encryptionSecret == nil {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err := file.Chmod(0600); err != nil {
+			return err
+		}
+		_, err = file.Write(data)
+		return err
+	}
+	existing, err := ReadSecureFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return WriteSecureFile(path, append(existing, data...))
+}
+
+// Reads a file written by writeSecureFile. Plain files are still readable
+// once encryption is enabled.
+func ReadSecureFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, encryptionMagic) {
+		return data, nil
+	}
+	if encryptionSecret == nil {
+		return nil, fmt.Errorf("`%s` is encrypted, set `EncryptionPassphrase` or `EncryptionKeyFile` in the config", path)
+	}
+	return decrypt(data)
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gpt/config"
+)
+
+// Enables encryption with passphrase for the duration of the test
+func setupEncryption(t *testing.T, passphrase string) {
+	t.Helper()
+	t.Cleanup(func() {
+		encryptionSecret = nil
+		encryptionSalt = nil
+	})
+	if err := SetupEncryption(config.Config{EncryptionPassphrase: passphrase}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSecureFileRoundTrip(t *testing.T) {
+	setupEncryption(t, "correct horse")
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := WriteSecureFile(path, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(raw, encryptionMagic) || bytes.Contains(raw, []byte("hello")) {
+		t.Errorf("file is not encrypted: %q", raw)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("file should only be readable by its owner, got %v", info.Mode().Perm())
+	}
+
+	if err := AppendSecureFile(path, []byte(" world")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ReadSecureFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("got %q", data)
+	}
+}
+
+func TestReadSecureFileErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	setupEncryption(t, "correct horse")
+	if err := WriteSecureFile(path, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	setupEncryption(t, "wrong")
+	if _, err := ReadSecureFile(path); err == nil || !strings.Contains(err.Error(), "wrong encryption key") {
+		t.Errorf("wrong passphrase, got error %v", err)
+	}
+	encryptionSecret = nil
+	if _, err := ReadSecureFile(path); err == nil || !strings.Contains(err.Error(), "is encrypted") {
+		t.Errorf("disabled encryption, got error %v", err)
+	}
+}
+
+func TestReadSecureFilePlain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := AppendSecureFile(path, []byte("plain")); err != nil {
+		t.Fatal(err)
+	}
+	setupEncryption(t, "correct horse")
+	data, err := ReadSecureFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "plain" {
+		t.Errorf("got %q", data)
+	}
+}
+
+// The pool workers of review, /compare and /recall read and write the cache
+// files at the same time
+func TestSecureFileConcurrent(t *testing.T) {
+	setupEncryption(t, "correct horse")
+	dir := t.TempDir()
+	wg := sync.WaitGroup{}
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := filepath.Join(dir, fmt.Sprint(i))
+			if err := WriteSecureFile(path, []byte("hello")); err != nil {
+				t.Error(err)
+				return
+			}
+			if data, err := ReadSecureFile(path); err != nil || string(data) != "hello" {
+				t.Errorf("got %q and error %v", data, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,139 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Version 1 is the original format: a bare array of API messages.
+// Version 2 wraps messages carrying metadata in an object.
+const HISTORY_SCHEMA_VERSION = 2
+
+type historyFile struct {
+	SchemaVersion int       `json:"schema_version"`
+	Messages      []Message `json:"messages"`
+}
+
+// Each migration upgrades a history file from the version it is keyed by to
+// the next one
+var historyMigrations = map[int]func([]byte) ([]byte, error){
+	1: migrateHistoryV1,
+}
+
+func EncodeHistory(messages []Message) ([]byte, error) {
+	return json.MarshalIndent(historyFile{
+		SchemaVersion: HISTORY_SCHEMA_VERSION,
+		Messages:      messages,
+	}, "", "  ")
+}
+
+func DecodeHistory(data []byte) ([]Message, error) {
+	version, err := historyVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version > HISTORY_SCHEMA_VERSION {
+		return nil, fmt.Errorf("schema version %d is newer than the supported version %d", version, HISTORY_SCHEMA_VERSION)
+	}
+	for ; version < HISTORY_SCHEMA_VERSION; version++ {
+		migrate, ok := historyMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration from schema version %d", version)
+		}
+		if data, err = migrate(data); err != nil {
+			return nil, fmt.Errorf("migrating from schema version %d: %w", version, err)
+		}
+	}
+
+	var file historyFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, err
+	}
+	return file.Messages, nil
+}
+
+func historyVersion(data []byte) (int, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return 1, nil
+	}
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(trimmed, &header); err != nil {
+		return 0, err
+	}
+	if header.SchemaVersion == 0 {
+		return 0, fmt.Errorf("missing schema_version")
+	}
+	return header.SchemaVersion, nil
+}
+
+func migrateHistoryV1(data []byte) ([]byte, error) {
+	var legacy []openai.ChatCompletionMessage
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil, err
+	}
+	messages := make([]Message, 0, len(legacy))
+	for _, msg := range legacy {
+		content := msg.Content
+		// Only the text of multi-part messages is kept
+		if content == "" && len(msg.MultiContent) > 0 {
+			parts := []string{}
+			for _, part := range msg.MultiContent {
+				if part.Type == openai.ChatMessagePartTypeText {
+					parts = append(parts, part.Text)
+				}
+			}
+			content = strings.Join(parts, "\n")
+		}
+		messages = append(messages, Message{Role: msg.Role, Content: content})
+	}
+	return json.Marshal(historyFile{SchemaVersion: 2, Messages: messages})
+}
+
+// The messages sent with each request. Unlike the messages of the session,
+// it is compacted: the oldest messages are folded into Summary.
+type History struct {
+	Messages []Message
+	// Summary of the messages dropped by Compact
+	Summary string
+}
+
+// Starts over from messages, without a summary
+func (h *History) Reset(messages []Message) {
+	h.Messages = append([]Message{}, messages...)
+	h.Summary = ""
+}
+
+func (h *History) Append(msg Message) {
+	h.Messages = append(h.Messages, msg)
+}
+
+// Removes the last message added with Append
+func (h *History) DropLast() {
+	h.Messages = h.Messages[:len(h.Messages)-1]
+}
+
+// Keeps at most max messages, 0 for no limit. The oldest ones are folded into
+// the summary by summarize, which merges them with the previous summary. The
+// history is unchanged when it fails.
+func (h *History) Compact(max int, summarize func(summary string, dropped []Message) (string, error)) error {
+	if max <= 0 || len(h.Messages) <= max {
+		return nil
+	}
+	dropped := h.Messages[:len(h.Messages)-max]
+	summary, err := summarize(h.Summary, dropped)
+	if err != nil {
+		return err
+	}
+	h.Summary = summary
+	h.Messages = append([]Message{}, h.Messages[len(dropped):]...)
+	return nil
+}
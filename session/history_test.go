@@ -0,0 +1,118 @@
+package session
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeHistory(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Message
+		err  string
+	}{
+		{
+			name: "version 1",
+			data: `[{"role": "user", "content": "hi"}, {"role": "assistant", "content": "Hello"}]`,
+			want: []Message{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "Hello"}},
+		},
+		{
+			name: "version 1 multi-part",
+			data: `[{"role": "user", "content": [{"type": "text", "text": "look"}, {"type": "image_url", "image_url": {"url": "data:"}}, {"type": "text", "text": "here"}]}]`,
+			want: []Message{{Role: "user", Content: "look\nhere"}},
+		},
+		{
+			name: "version 2",
+			data: `{"schema_version": 2, "messages": [{"role": "user", "content": "hi", "model": "gpt-4o"}]}`,
+			want: []Message{{Role: "user", Content: "hi", Model: "gpt-4o"}},
+		},
+		{
+			name: "newer version",
+			data: `{"schema_version": 3, "messages": []}`,
+			err:  "newer than the supported version",
+		},
+		{
+			name: "missing version",
+			data: `{"messages": []}`,
+			err:  "missing schema_version",
+		},
+		{
+			name: "unknown field",
+			data: `{"schema_version": 2, "messages": [], "extra": true}`,
+			err:  "unknown field",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			messages, err := DecodeHistory([]byte(test.data))
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("got error %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(messages, test.want) {
+				t.Errorf("got %+v, want %+v", messages, test.want)
+			}
+		})
+	}
+}
+
+func TestEncodeHistoryRoundTrip(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "hi", Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Role: "assistant", Content: "Hello", Model: "gpt-4o", CompletionTokens: 2, Seed: 42},
+	}
+	data, err := EncodeHistory(messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeHistory(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, messages) {
+		t.Errorf("got %+v, want %+v", decoded, messages)
+	}
+}
+
+func TestHistoryCompact(t *testing.T) {
+	messages := []Message{{Content: "1"}, {Content: "2"}, {Content: "3"}, {Content: "4"}}
+	h := History{}
+	h.Reset(messages)
+	h.Summary = "0"
+
+	summarize := func(summary string, dropped []Message) (string, error) {
+		for _, msg := range dropped {
+			summary += msg.Content
+		}
+		return summary, nil
+	}
+	if err := h.Compact(0, summarize); err != nil || len(h.Messages) != 4 {
+		t.Errorf("no limit, got %d messages and error %v", len(h.Messages), err)
+	}
+	if err := h.Compact(3, func(string, []Message) (string, error) { return "", errors.New("failed") }); err == nil || len(h.Messages) != 4 || h.Summary != "0" {
+		t.Errorf("failed summary, got %d messages, summary %q and error %v", len(h.Messages), h.Summary, err)
+	}
+	if err := h.Compact(2, summarize); err != nil {
+		t.Fatal(err)
+	}
+	if h.Summary != "012" || !reflect.DeepEqual(h.Messages, messages[2:]) {
+		t.Errorf("got summary %q and messages %+v", h.Summary, h.Messages)
+	}
+	if messages[0].Content != "1" {
+		t.Errorf("the messages given to Reset were modified: %+v", messages)
+	}
+
+	h.Reset(nil)
+	if h.Summary != "" || len(h.Messages) != 0 {
+		t.Errorf("Reset kept summary %q and %d messages", h.Summary, len(h.Messages))
+	}
+}
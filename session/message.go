@@ -0,0 +1,42 @@
+package session
+
+import (
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// A message of the conversation along with its metadata. Only the role and
+// content are sent to the API.
+type Message struct {
+	Role             string    `json:"role"`
+	Content          string    `json:"content"`
+	Time             time.Time `json:"time,omitempty"`
+	Model            string    `json:"model,omitempty"`
+	PromptTokens     int       `json:"prompt_tokens,omitempty"`
+	CompletionTokens int       `json:"completion_tokens,omitempty"`
+	LatencyMs        int64     `json:"latency_ms,omitempty"`
+	// Needed to reproduce a response along with the request
+	Seed              int    `json:"seed,omitempty"`
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
+}
+
+func NewMessage(role string, content string) Message {
+	return Message{Role: role, Content: content, Time: time.Now()}
+}
+
+func (m Message) ChatMessage() openai.ChatCompletionMessage {
+	return openai.ChatCompletionMessage{Role: m.Role, Content: m.Content}
+}
+
+// Token count of the message: exact for responses, estimated otherwise
+func (m Message) Tokens() (int, bool) {
+	if m.CompletionTokens > 0 {
+		return m.CompletionTokens, true
+	}
+	return EstimateTokens(m.Content), false
+}
+
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
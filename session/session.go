@@ -0,0 +1,142 @@
+// Package session stores conversations: messages, archived sessions and
+// history files, encrypted when a key is configured.
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DIR = "sessions"
+	// Embeddings of the archived sessions, used by `/recall`
+	INDEX_FILE = "sessions/index.json"
+	ID_FORMAT  = "20060102-150405"
+)
+
+// Every conversation is archived in DIR as `<ID>.json`. Unlike
+// the history, which is compacted, Messages holds the whole transcript.
+type Session struct {
+	SchemaVersion int           `json:"schema_version"`
+	ID            string        `json:"id"`
+	Title         string        `json:"title,omitempty"`
+	Created       time.Time     `json:"created"`
+	Updated       time.Time     `json:"updated"`
+	Model         string        `json:"model"`
+	SystemPrompt  string        `json:"system_prompt"`
+	Messages      []Message     `json:"messages"`
+	Context       []ContextItem `json:"context,omitempty"`
+}
+
+func New() *Session {
+	now := time.Now()
+	return &Session{
+		ID:      now.Format(ID_FORMAT),
+		Created: now,
+	}
+}
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Whether id can name a session file, e.g. not `../gpt_memory`
+func ValidID(id string) bool {
+	return validID.MatchString(id)
+}
+
+func Path(id string) string {
+	return filepath.Join(DIR, id+".json")
+}
+
+func Write(session *Session) error {
+	session.SchemaVersion = HISTORY_SCHEMA_VERSION
+	if err := os.MkdirAll(DIR, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteSecureFile(Path(session.ID), data)
+}
+
+func Read(id string) (*Session, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid session ID `%s`", id)
+	}
+	data, err := ReadSecureFile(Path(id))
+	if err != nil {
+		return nil, err
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	// Sessions were introduced with version 2 messages
+	if session.SchemaVersion > HISTORY_SCHEMA_VERSION {
+		return nil, fmt.Errorf("schema version %d is newer than the supported version %d", session.SchemaVersion, HISTORY_SCHEMA_VERSION)
+	}
+	return &session, nil
+}
+
+// Returns the archived sessions, most recently updated first
+func List() ([]*Session, error) {
+	entries, err := os.ReadDir(DIR)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []*Session{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") || name == filepath.Base(INDEX_FILE) {
+			continue
+		}
+		session, err := Read(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Updated.After(sessions[j].Updated)
+	})
+	return sessions, nil
+}
+
+func (s *Session) DisplayName() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return s.ID
+}
+
+const (
+	CONTEXT_FILE       = "file"
+	CONTEXT_PASTE      = "paste"
+	CONTEXT_CLIPBOARD  = "clipboard"
+	CONTEXT_EXCERPT    = "excerpt"
+	CONTEXT_TRANSCRIPT = "transcript"
+	CONTEXT_COMMAND    = "command"
+	CONTEXT_PANE       = "pane"
+)
+
+// A piece of context added to the system prompt, e.g. an embedded file
+type ContextItem struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// File the content was read from, if any
+	Path    string `json:"path,omitempty"`
+	Content string `json:"content"`
+	// Watched files are read again before a request when they change on disk
+	Watch   bool      `json:"watch,omitempty"`
+	ModTime time.Time `json:"mod_time,omitempty"`
+}
@@ -0,0 +1,191 @@
+// Package tools runs the functions the model calls during a conversation,
+// with a cache of their results, and the loop sending the results back.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"gpt/chat"
+)
+
+// Stops runaway loops of tool calls
+const MAX_TOOL_ROUNDS = 10
+
+// A function the model can call during a conversation
+type Tool struct {
+	Description string
+	// JSON schema of the arguments
+	Parameters json.RawMessage
+	Run        func(args string) (string, error)
+	// Results of deterministic tools, e.g. web fetches or file reads, are
+	// reused for this long when called again with the same arguments
+	CacheTTL time.Duration
+}
+
+var registry = map[string]Tool{}
+
+// Makes a tool available to the model, replacing the one with the same name
+func Register(name string, tool Tool) {
+	registry[name] = tool
+}
+
+type cachedResult struct {
+	Result  string
+	Expires time.Time
+}
+
+// Tool results of the session, by tool and arguments
+var (
+	cache      = map[string]cachedResult{}
+	cacheMutex sync.Mutex
+)
+
+// Arguments are normalized so that key order and spacing don't matter
+func cacheKey(call openai.ToolCall) string {
+	args := call.Function.Arguments
+	var value any
+	if err := json.Unmarshal([]byte(args), &value); err == nil {
+		if normalized, err := json.Marshal(value); err == nil {
+			args = string(normalized)
+		}
+	}
+	return call.Function.Name + "\x00" + args
+}
+
+// The registered tools sorted by name, nil when there are none
+func Definitions() []openai.Tool {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	definitions := []openai.Tool{}
+	for _, name := range names {
+		definitions = append(definitions, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        name,
+				Description: registry[name].Description,
+				Parameters:  registry[name].Parameters,
+			},
+		})
+	}
+	if len(definitions) == 0 {
+		return nil
+	}
+	return definitions
+}
+
+// Drops the cached results of the tools, e.g. reads after a write
+func Invalidate(names ...string) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	for key := range cache {
+		name, _, _ := strings.Cut(key, "\x00")
+		for _, n := range names {
+			if name == n {
+				delete(cache, key)
+			}
+		}
+	}
+}
+
+// The result is sent back to the model, errors included. Cached is true when
+// the result comes from the cache.
+func Run(call openai.ToolCall) (result string, cached bool) {
+	_, span := chat.Tracer.Start(context.Background(), "execute_tool "+call.Function.Name, trace.WithAttributes(
+		attribute.String("gen_ai.operation.name", "execute_tool"),
+		attribute.String("gen_ai.tool.name", call.Function.Name),
+	))
+	var err error
+	defer func() {
+		span.SetAttributes(attribute.Bool("cached", cached))
+		chat.EndSpan(span, err)
+	}()
+
+	tool, ok := registry[call.Function.Name]
+	if !ok {
+		err = fmt.Errorf("unknown tool `%s`", call.Function.Name)
+		return "Error: " + err.Error(), false
+	}
+	key := cacheKey(call)
+	cacheMutex.Lock()
+	entry, found := cache[key]
+	cacheMutex.Unlock()
+	if found && time.Now().Before(entry.Expires) {
+		return entry.Result, true
+	}
+	result, err = tool.Run(call.Function.Arguments)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), false
+	}
+	if tool.CacheTTL > 0 {
+		cacheMutex.Lock()
+		cache[key] = cachedResult{Result: result, Expires: time.Now().Add(tool.CacheTTL)}
+		cacheMutex.Unlock()
+	}
+	return result, false
+}
+
+// Receives the tool calls as they are streamed, the end of each round of
+// calls and the results of the calls
+type Events interface {
+	Chunk(index int, name string, args string)
+	Done()
+	Result(call openai.ToolCall, result string, cached bool)
+}
+
+// Streams the response to the request, running the tools the model calls
+// and sending their results back until it answers. Tool calls only live in
+// the request, the history gets the final answer.
+func Stream(client *openai.Client, req openai.ChatCompletionRequest, onChunk func(string), events Events) (string, chat.ResponseStats, error) {
+	req.Tools = Definitions()
+	total := chat.ResponseStats{Model: req.Model}
+	start := time.Now()
+	for round := 1; ; round++ {
+		// The last round has to answer
+		if round == MAX_TOOL_ROUNDS {
+			req.Tools = nil
+		}
+		response, calls, stats, err := chat.StreamCompletion(client, req, onChunk, events.Chunk)
+		events.Done()
+
+		if total.TimeToFirstToken == 0 && stats.TimeToFirstToken != 0 {
+			total.TimeToFirstToken = time.Since(start) - stats.Duration + stats.TimeToFirstToken
+		}
+		total.PromptTokens += stats.PromptTokens
+		total.CompletionTokens += stats.CompletionTokens
+		total.Seed = stats.Seed
+		total.SystemFingerprint = stats.SystemFingerprint
+		total.Duration = time.Since(start)
+		if err != nil || len(calls) == 0 {
+			return response, total, err
+		}
+
+		req.Messages = append(req.Messages, openai.ChatCompletionMessage{
+			Role:      openai.ChatMessageRoleAssistant,
+			Content:   response,
+			ToolCalls: calls,
+		})
+		for _, call := range calls {
+			result, cached := Run(call)
+			events.Result(call, result, cached)
+			req.Messages = append(req.Messages, openai.ChatCompletionMessage{
+				Role:       openai.ChatMessageRoleTool,
+				Content:    result,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func toolCall(name string, args string) openai.ToolCall {
+	return openai.ToolCall{Function: openai.FunctionCall{Name: name, Arguments: args}}
+}
+
+func TestRunCache(t *testing.T) {
+	runs := 0
+	Register("count", Tool{
+		Run: func(args string) (string, error) {
+			runs++
+			return args, nil
+		},
+		CacheTTL: time.Minute,
+	})
+	defer delete(registry, "count")
+
+	if result, cached := Run(toolCall("count", `{"a": 1, "b": 2}`)); cached || result != `{"a": 1, "b": 2}` {
+		t.Errorf("first call, got %q and cached %v", result, cached)
+	}
+	// Same arguments in another order and spacing
+	if _, cached := Run(toolCall("count", `{"b":2,"a":1}`)); !cached || runs != 1 {
+		t.Errorf("second call, got cached %v after %d runs", cached, runs)
+	}
+	Invalidate("other")
+	if _, cached := Run(toolCall("count", `{"a": 1, "b": 2}`)); !cached {
+		t.Error("invalidating another tool dropped the result")
+	}
+	Invalidate("count")
+	if _, cached := Run(toolCall("count", `{"a": 1, "b": 2}`)); cached || runs != 2 {
+		t.Errorf("after Invalidate, got cached %v after %d runs", cached, runs)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	if result, _ := Run(toolCall("missing", "{}")); !strings.Contains(result, "unknown tool `missing`") {
+		t.Errorf("unknown tool, got %q", result)
+	}
+
+	runs := 0
+	Register("fail", Tool{
+		Run: func(args string) (string, error) {
+			runs++
+			return "", errors.New("test")
+		},
+		CacheTTL: time.Minute,
+	})
+	defer delete(registry, "fail")
+	Run(toolCall("fail", "{}"))
+	if result, cached := Run(toolCall("fail", "{}")); cached || result != "Error: test" || runs != 2 {
+		t.Errorf("errors should not be cached, got %q and cached %v after %d runs", result, cached, runs)
+	}
+}
+
+func TestDefinitions(t *testing.T) {
+	if Definitions() != nil {
+		t.Fatal("tools are registered by other tests")
+	}
+	Register("b", Tool{Description: "B"})
+	Register("a", Tool{Description: "A"})
+	defer delete(registry, "a")
+	defer delete(registry, "b")
+	definitions := Definitions()
+	if len(definitions) != 2 || definitions[0].Function.Name != "a" || definitions[1].Function.Description != "B" {
+		t.Errorf("got %+v", definitions)
+	}
+}